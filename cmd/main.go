@@ -1,13 +1,52 @@
 package main
 
 import (
+	auditHandlers "DBForum/internal/app/audit/handlers"
+	auditRepo "DBForum/internal/app/audit/repository"
+	auditUCase "DBForum/internal/app/audit/usecase"
+	authHandlers "DBForum/internal/app/auth/handlers"
+	authRepo "DBForum/internal/app/auth/repository"
+	authUCase "DBForum/internal/app/auth/usecase"
+	banHandlers "DBForum/internal/app/ban/handlers"
+	banRepo "DBForum/internal/app/ban/repository"
+	banUCase "DBForum/internal/app/ban/usecase"
+	blockHandlers "DBForum/internal/app/block/handlers"
+	blockRepo "DBForum/internal/app/block/repository"
+	blockUCase "DBForum/internal/app/block/usecase"
+	"DBForum/internal/app/config"
+	conversationHandlers "DBForum/internal/app/conversation/handlers"
+	conversationRepo "DBForum/internal/app/conversation/repository"
+	conversationUCase "DBForum/internal/app/conversation/usecase"
 	"DBForum/internal/app/database"
+	filterHandlers "DBForum/internal/app/filter/handlers"
+	filterRepo "DBForum/internal/app/filter/repository"
+	filterUCase "DBForum/internal/app/filter/usecase"
 	forumHandlers "DBForum/internal/app/forum/handlers"
 	forumRepo "DBForum/internal/app/forum/repository"
 	forumUCase "DBForum/internal/app/forum/usecase"
+	"DBForum/internal/app/httputils"
+	mentionHandlers "DBForum/internal/app/mention/handlers"
+	mentionRepo "DBForum/internal/app/mention/repository"
+	mentionUCase "DBForum/internal/app/mention/usecase"
+	"DBForum/internal/app/middleware"
+	notificationHandlers "DBForum/internal/app/notification/handlers"
+	notificationRepo "DBForum/internal/app/notification/repository"
+	notificationUCase "DBForum/internal/app/notification/usecase"
 	postHandlers "DBForum/internal/app/post/handlers"
 	postRepo "DBForum/internal/app/post/repository"
 	postUCase "DBForum/internal/app/post/usecase"
+	reportHandlers "DBForum/internal/app/report/handlers"
+	reportRepo "DBForum/internal/app/report/repository"
+	reportUCase "DBForum/internal/app/report/usecase"
+	revisionHandlers "DBForum/internal/app/revision/handlers"
+	revisionRepo "DBForum/internal/app/revision/repository"
+	revisionUCase "DBForum/internal/app/revision/usecase"
+	roleHandlers "DBForum/internal/app/role/handlers"
+	roleRepo "DBForum/internal/app/role/repository"
+	roleUCase "DBForum/internal/app/role/usecase"
+	searchHandlers "DBForum/internal/app/search/handlers"
+	searchRepo "DBForum/internal/app/search/repository"
+	searchUCase "DBForum/internal/app/search/usecase"
 	serviceHandlers "DBForum/internal/app/service/handlers"
 	serviceRepo "DBForum/internal/app/service/repository"
 	serviceUCase "DBForum/internal/app/service/usecase"
@@ -16,12 +55,17 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 
+	subscriptionHandlers "DBForum/internal/app/subscription/handlers"
+	subscriptionRepo "DBForum/internal/app/subscription/repository"
+	subscriptionUCase "DBForum/internal/app/subscription/usecase"
+
 	threadHandlers "DBForum/internal/app/thread/handlers"
 	threadRepo "DBForum/internal/app/thread/repository"
 	threadUCase "DBForum/internal/app/thread/usecase"
 
 	userHandlers "DBForum/internal/app/user/handlers"
 	userRepo "DBForum/internal/app/user/repository"
+	"DBForum/internal/app/user/storage"
 	userUCase "DBForum/internal/app/user/usecase"
 
 	"log"
@@ -29,25 +73,78 @@ import (
 )
 
 func main() {
+	conf := config.NewConfig()
 
-	postgres, err := database.NewPostgres()
+	postgres, err := database.NewPostgres(conf)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	auditRepository := auditRepo.NewRepo(postgres.GetPostgres())
+	if err := auditRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
+	authRepository := authRepo.NewRepo(postgres.GetPostgres())
+	if err := authRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
+	banRepository := banRepo.NewRepo(postgres.GetPostgres())
+	if err := banRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
+	blockRepository := blockRepo.NewRepo(postgres.GetPostgres())
+	if err := blockRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
+	conversationRepository := conversationRepo.NewRepo(postgres.GetPostgres())
+	if err := conversationRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
+	filterRepository := filterRepo.NewRepo(postgres.GetPostgres())
+	if err := filterRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
 	forumRepository := forumRepo.NewRepo(postgres.GetPostgres())
 	if err := forumRepository.Prepare(); err != nil {
 		log.Fatalln(err)
 	}
+	mentionRepository := mentionRepo.NewRepo(postgres.GetPostgres())
+	if err := mentionRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
+	notificationRepository := notificationRepo.NewRepo(postgres.GetPostgres())
+	if err := notificationRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
 	postRepository := postRepo.NewRepo(postgres.GetPostgres())
 	if err := postRepository.Prepare(); err != nil {
 		log.Fatalln(err)
 	}
+	reportRepository := reportRepo.NewRepo(postgres.GetPostgres())
+	if err := reportRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
+	revisionRepository := revisionRepo.NewRepo(postgres.GetPostgres())
+	if err := revisionRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
+	roleRepository := roleRepo.NewRepo(postgres.GetPostgres())
+	if err := roleRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
+	searchRepository := searchRepo.NewRepo(postgres.GetPostgres())
+	if err := searchRepository.Prepare(); err != nil {
+		log.Fatalln(err)
+	}
 	serviceRepository := serviceRepo.NewRepo(postgres.GetPostgres())
 	if err := serviceRepository.Prepare(); err != nil {
 		log.Fatalln(err)
 	}
+	subscriptionRepository := subscriptionRepo.NewRepo(postgres.GetPostgres())
+	if err := subscriptionRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
 	threadRepository := threadRepo.NewRepo(postgres.GetPostgres())
 	if err := threadRepository.Prepare(); err != nil {
 		log.Fatalln(err)
@@ -57,50 +154,155 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	forumUseCase := forumUCase.NewUseCase(*forumRepository, *userRepository, *threadRepository)
-	postUseCase := postUCase.NewUseCase(*postRepository, *userRepository, *threadRepository, *forumRepository)
+	auditUseCase := auditUCase.NewUseCase(*auditRepository)
+	authUseCase := authUCase.NewUseCase(*authRepository, *userRepository, conf.Auth)
+	banUseCase := banUCase.NewUseCase(*banRepository)
+	blockUseCase := blockUCase.NewUseCase(*blockRepository, conf.Block)
+	conversationUseCase := conversationUCase.NewUseCase(*conversationRepository)
+	// Собственные классификаторы передаются в filterUCase.NewUseCase после
+	// настроек и выполняются после встроенных фильтров.
+	filterUseCase := filterUCase.NewUseCase(*filterRepository, conf.Filter)
+	forumUseCase := forumUCase.NewUseCase(*forumRepository, *userRepository, *threadRepository, *filterUseCase, *blockUseCase)
+	mentionUseCase := mentionUCase.NewUseCase(*mentionRepository)
+	notificationUseCase := notificationUCase.NewUseCase(*notificationRepository)
+	postUseCase := postUCase.NewUseCase(*postRepository, *userRepository, *threadRepository, *forumRepository, conf.Edit)
+	reportUseCase := reportUCase.NewUseCase(*reportRepository)
+	revisionUseCase := revisionUCase.NewUseCase(*revisionRepository)
+	roleUseCase := roleUCase.NewUseCase(*roleRepository)
+	searchUseCase := searchUCase.NewUseCase(*searchRepository)
 	serviceUseCase := serviceUCase.NewUseCase(*serviceRepository)
-	threadUseCase := threadUCase.NewUseCase(*threadRepository, *postRepository)
-	userUseCase := userUCase.NewUseCase(*userRepository)
-
-	forumHandler := forumHandlers.NewHandler(*forumUseCase)
+	subscriptionUseCase := subscriptionUCase.NewUseCase(*subscriptionRepository)
+	threadUseCase := threadUCase.NewUseCase(*threadRepository, *postRepository, conf.Vote, conf.Edit, *filterUseCase, *blockUseCase)
+	avatarStore, err := storage.NewDisk(conf.Avatar.Dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	userUseCase := userUCase.NewUseCase(*userRepository, *threadRepository, *postRepository, avatarStore, conf.Avatar, conf.Rename)
+
+	pager := httputils.NewPager(conf.Page)
+	auditHandler := auditHandlers.NewHandler(*auditUseCase, pager)
+	authHandler := authHandlers.NewHandler(*authUseCase)
+	banHandler := banHandlers.NewHandler(*banUseCase, pager)
+	blockHandler := blockHandlers.NewHandler(*blockUseCase)
+	conversationHandler := conversationHandlers.NewHandler(*conversationUseCase, pager)
+	filterHandler := filterHandlers.NewHandler(*filterUseCase)
+	forumHandler := forumHandlers.NewHandler(*forumUseCase, pager)
+	mentionHandler := mentionHandlers.NewHandler(*mentionUseCase, pager)
+	notificationHandler := notificationHandlers.NewHandler(*notificationUseCase, pager)
 	postHandler := postHandlers.NewHandler(*postUseCase)
+	reportHandler := reportHandlers.NewHandler(*reportUseCase, pager)
+	revisionHandler := revisionHandlers.NewHandler(*revisionUseCase)
+	roleHandler := roleHandlers.NewHandler(*roleUseCase)
+	searchHandler := searchHandlers.NewHandler(*searchUseCase, pager)
 	serviceHandler := serviceHandlers.NewHandler(*serviceUseCase)
-	threadHandler := threadHandlers.NewHandler(*threadUseCase)
-	userHandler := userHandlers.NewHandler(*userUseCase)
+	subscriptionHandler := subscriptionHandlers.NewHandler(*subscriptionUseCase, pager)
+	threadHandler := threadHandlers.NewHandler(*threadUseCase, pager)
+	userHandler := userHandlers.NewHandler(*userUseCase, pager)
+
+	auth := middleware.NewAuth(*authUseCase, conf.Auth.Required)
+	perms := middleware.NewPermissions(*roleUseCase)
+	write := func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return auth.Handle(perms.Restrict(roleUCase.PermWrite, h))
+	}
+	moderator := func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return auth.RequireAuth(perms.Require(roleUCase.PermModerate, h))
+	}
+	admin := func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return auth.RequireAuth(perms.Require(roleUCase.PermAdmin, h))
+	}
+	// Операции только от своего имени: без токена не выполняются.
+	private := func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return auth.RequireAuth(perms.Restrict(roleUCase.PermWrite, h))
+	}
+	limiter := middleware.NewRateLimiter(conf.Rate, func(idOrSlug string) (string, error) {
+		thread, err := threadUseCase.FindThread(idOrSlug)
+		if err != nil {
+			return "", err
+		}
+		return thread.Forum, nil
+	})
+	limited := func(class string, h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return write(limiter.Limit(class, h))
+	}
 
 	//router := mux.NewRouter()
 	router := router2.New()
 
+	router.POST("/api/auth/login", authHandler.Login)
+
+	router.POST("/api/auth/refresh", authHandler.Refresh)
+
+	router.POST("/api/auth/logout", auth.RequireAuth(authHandler.Logout))
+
+	router.POST("/api/admin/roles/grant", admin(roleHandler.Grant))
+
+	router.POST("/api/admin/roles/revoke", admin(roleHandler.Revoke))
+
+	router.GET("/api/admin/audit", admin(auditHandler.GetAuditLog))
+
 	//router.Use(commonMiddleware)
 	//forum := router.PathPrefix("/api/forum").Subrouter()
 
 	//done
-	router.POST("/api/forum/create", forumHandler.Create)
+	router.POST("/api/forum/create", write(forumHandler.Create))
 
 	//done
 	router.GET("/api/forum/{slug}/details", forumHandler.Details)
 
 	//done
-	router.POST("/api/forum/{slug}/create", forumHandler.CreateThread)
+	router.POST("/api/forum/{slug}/create", limited(middleware.RateThread, forumHandler.CreateThread))
 
 	//done
-	router.GET("/api/forum/{slug}/users", forumHandler.GetUsers)
+	router.GET("/api/forum/{slug}/users", auth.Optional(forumHandler.GetUsers))
 
 	//done
-	router.GET("/api/forum/{slug}/threads", forumHandler.GetThreads)
+	router.GET("/api/forum/{slug}/threads", auth.Optional(forumHandler.GetThreads))
+
+	router.POST("/api/forum/{slug}/ban", moderator(banHandler.Ban))
+
+	router.POST("/api/forum/{slug}/unban", moderator(banHandler.Unban))
+
+	router.GET("/api/forum/{slug}/bans", moderator(banHandler.GetBans))
+
+	router.GET("/api/forum/{slug}/reports", moderator(reportHandler.GetQueue))
+
+	router.POST("/api/forum/{slug}/reports/resolve", moderator(reportHandler.Resolve))
+
+	router.GET("/api/forum/{slug}/filters", moderator(filterHandler.GetWords))
+
+	router.POST("/api/forum/{slug}/filters", moderator(filterHandler.SetWord))
+
+	router.POST("/api/forum/{slug}/filters/delete", moderator(filterHandler.DeleteWord))
+
+	router.POST("/api/forum/{slug}/subscribe", private(subscriptionHandler.SubscribeForum))
+
+	router.POST("/api/forum/{slug}/unsubscribe", private(subscriptionHandler.UnsubscribeForum))
+
+	router.POST("/api/bans/ban", moderator(banHandler.Ban))
+
+	router.POST("/api/bans/unban", moderator(banHandler.Unban))
+
+	router.GET("/api/bans", moderator(banHandler.GetBans))
 
 	//post := router.PathPrefix("/api/post").Subrouter()
 
-	router.GET("/api/post/{id}/details", postHandler.GetInfo)
+	router.GET("/api/post/{id}/details", auth.Optional(perms.Load(postHandler.GetInfo)))
 
 	//done
-	router.POST("/api/post/{id}/details", postHandler.ChangeMessage)
+	router.POST("/api/post/{id}/details", write(postHandler.ChangeMessage))
+
+	router.POST("/api/post/{id}/report", write(reportHandler.ReportPost))
+
+	router.GET("/api/post/{id}/revisions", auth.Optional(perms.Load(revisionHandler.GetPostRevisions)))
+
+	router.GET("/api/post/{id}/revisions/diff", auth.Optional(perms.Load(revisionHandler.DiffPostRevisions)))
+
+	router.GET("/api/search", searchHandler.Search)
 
 	//service := router.PathPrefix("/api/service").Subrouter()
 
 	//done
-	router.POST("/api/service/clear", serviceHandler.ClearDB)
+	router.POST("/api/service/clear", admin(serviceHandler.ClearDB))
 
 	//done
 	router.GET("/api/service/status", serviceHandler.Status)
@@ -108,19 +310,29 @@ func main() {
 	//thread := router.PathPrefix("/api/thread").Subrouter()
 
 	//done
-	router.POST("/api/thread/{slug_or_id}/create", threadHandler.CreatePost)
+	router.POST("/api/thread/{slug_or_id}/create", limited(middleware.RatePost, threadHandler.CreatePost))
 
 	//done
-	router.GET("/api/thread/{slug_or_id}/details", threadHandler.ThreadInfo)
+	router.GET("/api/thread/{slug_or_id}/details", auth.Optional(perms.Load(threadHandler.ThreadInfo)))
 
 	//done
-	router.POST("/api/thread/{slug_or_id}/details", threadHandler.ChangeThread)
+	router.POST("/api/thread/{slug_or_id}/details", write(threadHandler.ChangeThread))
 
 	//done
-	router.GET("/api/thread/{slug_or_id}/posts", threadHandler.GetPosts)
+	router.GET("/api/thread/{slug_or_id}/posts", auth.Optional(perms.Load(threadHandler.GetPosts)))
 
 	//done
-	router.POST("/api/thread/{slug_or_id}/vote", threadHandler.VoteThread)
+	router.POST("/api/thread/{slug_or_id}/vote", limited(middleware.RateVote, threadHandler.VoteThread))
+
+	router.POST("/api/thread/{slug_or_id}/report", write(reportHandler.ReportThread))
+
+	router.POST("/api/thread/{slug_or_id}/subscribe", private(subscriptionHandler.SubscribeThread))
+
+	router.POST("/api/thread/{slug_or_id}/unsubscribe", private(subscriptionHandler.UnsubscribeThread))
+
+	router.GET("/api/thread/{slug_or_id}/revisions", auth.Optional(perms.Load(revisionHandler.GetThreadRevisions)))
+
+	router.GET("/api/thread/{slug_or_id}/revisions/diff", auth.Optional(perms.Load(revisionHandler.DiffThreadRevisions)))
 
 	//user := router.PathPrefix("/api/user").Subrouter()
 
@@ -128,13 +340,63 @@ func main() {
 	router.POST("/api/user/{nickname}/create", userHandler.CreateUser)
 
 	//done
-	router.GET("/api/user/{nickname}/profile", userHandler.GetUserInfo)
+	router.GET("/api/user/{nickname}/profile", auth.Optional(userHandler.GetUserInfo))
 
 	//done
-	router.POST("/api/user/{nickname}/profile", userHandler.ChangeUser)
+	router.POST("/api/user/{nickname}/profile", limited(middleware.RateProfile, userHandler.ChangeUser))
+
+	router.POST("/api/user/{nickname}/rename", private(limiter.Limit(middleware.RateProfile, userHandler.RenameUser)))
+
+	router.POST("/api/user/{nickname}/delete", auth.RequireAuth(perms.Load(userHandler.DeleteUser)))
+
+	router.GET("/api/user/{nickname}/export", auth.RequireAuth(perms.Load(userHandler.ExportUser)))
+
+	router.POST("/api/user/{nickname}/avatar", limited(middleware.RateProfile, userHandler.SetAvatar))
+
+	router.POST("/api/user/{nickname}/avatar/delete", limited(middleware.RateProfile, userHandler.DeleteAvatar))
+
+	router.ServeFiles(storage.AvatarPrefix+"{filepath:*}", conf.Avatar.Dir)
+
+	router.GET("/api/user/{nickname}/threads", userHandler.GetThreads)
+
+	router.GET("/api/user/{nickname}/posts", userHandler.GetPosts)
+
+	router.GET("/api/user/{nickname}/mentions", mentionHandler.GetMentions)
+
+	router.GET("/api/user/{nickname}/notifications", auth.RequireAuth(notificationHandler.GetNotifications))
+
+	router.GET("/api/user/{nickname}/notifications/unread", auth.RequireAuth(notificationHandler.GetUnreadCount))
+
+	router.POST("/api/user/{nickname}/notifications/read", auth.RequireAuth(notificationHandler.MarkRead))
+
+	router.POST("/api/user/{nickname}/notifications/read-all", auth.RequireAuth(notificationHandler.MarkAllRead))
+
+	router.GET("/api/user/{nickname}/subscriptions", auth.RequireAuth(subscriptionHandler.GetSubscriptions))
+
+	router.GET("/api/user/{nickname}/watched", auth.RequireAuth(subscriptionHandler.GetWatched))
+
+	router.GET("/api/user/{nickname}/conversations", auth.RequireAuth(conversationHandler.GetConversations))
+
+	router.GET("/api/user/{nickname}/blocks", auth.RequireAuth(blockHandler.GetBlocks))
+
+	router.POST("/api/user/{nickname}/blocks", private(blockHandler.Block))
+
+	router.POST("/api/user/{nickname}/blocks/delete", private(blockHandler.Unblock))
+
+	router.POST("/api/conversations/create", private(conversationHandler.Create))
+
+	router.POST("/api/conversations/{id}/send", private(conversationHandler.Send))
+
+	router.POST("/api/conversations/{id}/leave", private(conversationHandler.Leave))
+
+	router.GET("/api/conversations/{id}/messages", auth.RequireAuth(conversationHandler.GetMessages))
+
+	router.GET("/api/user/{nickname}/roles", roleHandler.GetUserRoles)
+
+	router.GET("/api/users", auth.Handle(perms.Load(userHandler.SearchUsers)))
 
 	fmt.Printf("Starting server on port %s\n", ":5000")
-	if err := fasthttp.ListenAndServe(":5000", router.Handler); err != nil {
+	if err := fasthttp.ListenAndServe(":5000", middleware.RequestID(router.Handler)); err != nil {
 		log.Fatal(err)
 	}
 }
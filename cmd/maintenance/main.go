@@ -0,0 +1,96 @@
+package main
+
+import (
+	"DBForum/internal/app/config"
+	"DBForum/internal/app/database"
+	"DBForum/internal/app/models"
+	postRepo "DBForum/internal/app/post/repository"
+	roleRepo "DBForum/internal/app/role/repository"
+	roleUCase "DBForum/internal/app/role/usecase"
+	threadRepo "DBForum/internal/app/thread/repository"
+	userRepo "DBForum/internal/app/user/repository"
+	userUCase "DBForum/internal/app/user/usecase"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+const usage = `Usage: maintenance <command> [args]
+
+Commands:
+  recompute-reputation   rebuild users reputation from dbforum.votes
+  grant-admin <nickname> grant the admin role, e.g. to bootstrap the first admin
+  check-forum-users      report forum_users rows that drifted from users, threads and posts
+  repair-forum-users     fix the drift reported by check-forum-users
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conf := config.NewConfig()
+	postgres, err := database.NewPostgres(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer postgres.Close()
+
+	userRepository := userRepo.NewRepo(postgres.GetPostgres())
+	threadRepository := threadRepo.NewRepo(postgres.GetPostgres())
+	postRepository := postRepo.NewRepo(postgres.GetPostgres())
+	// Команды не работают с аватарами, хранилище не нужно.
+	userUseCase := userUCase.NewUseCase(*userRepository, *threadRepository, *postRepository, nil, conf.Avatar, conf.Rename)
+	roleRepository := roleRepo.NewRepo(postgres.GetPostgres())
+	if err := roleRepository.Prepare(); err != nil {
+		log.Fatal(err)
+	}
+	roleUseCase := roleUCase.NewUseCase(*roleRepository)
+
+	switch flag.Arg(0) {
+	case "recompute-reputation":
+		updated, err := userUseCase.RecomputeReputation()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Reputation recomputed, %d users updated\n", updated)
+	case "grant-admin":
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		role := models.Role{Nickname: flag.Arg(1), Role: roleUCase.RoleAdmin}
+		if err := roleUseCase.GrantRole(role, models.Actor{}); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("User %s is now an admin\n", role.Nickname)
+	case "check-forum-users":
+		drift, err := userUseCase.CheckForumUsers()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, d := range drift {
+			fmt.Printf("%s\t%s\t%s\n", d.Kind, d.Forum, d.Nickname)
+		}
+		fmt.Printf("%d forum users out of sync\n", len(drift))
+		if len(drift) > 0 {
+			os.Exit(1)
+		}
+	case "repair-forum-users":
+		repair, err := userUseCase.RepairForumUsers()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Forum users repaired: %d stale, %d missing, %d orphaned\n",
+			repair.Stale, repair.Missing, repair.Orphaned)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -15,4 +15,5 @@ require (
 	github.com/shopspring/decimal v1.2.0 // indirect
 	github.com/sirupsen/logrus v1.8.1
 	github.com/valyala/fasthttp v1.26.0
+	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a
 )
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	auditUseCase "DBForum/internal/app/audit/usecase"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/models"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase auditUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase auditUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+func (h *Handlers) GetAuditLog(ctx *fasthttp.RequestCtx) {
+	// since - идентификатор записи, после которой будут выводиться записи
+	// (запись с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+	args := ctx.QueryArgs()
+	query := models.AuditQuery{
+		Actor: string(args.Peek("actor")),
+		// Тип объекта: user, forum, thread, post, vote, ban, role, report.
+		TargetType: string(args.Peek("type")),
+		TargetID:   string(args.Peek("target")),
+		Forum:      string(args.Peek("forum")),
+		RequestID:  string(args.Peek("request")),
+		Limit:      page.Limit,
+		Since:      page.SinceID,
+		Desc:       page.Desc,
+	}
+
+	var entries models.AuditLog
+	entries, err := h.useCase.GetAuditLog(query)
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, entries)
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"DBForum/internal/app/models"
+	"encoding/json"
+	"github.com/jackc/pgx"
+)
+
+const (
+	auditColumns = "id, actor, action, target_type, target_id, forum_slug, details, " +
+		"COALESCE(before::text, ''), COALESCE(after::text, ''), request_id, created"
+
+	insertAuditEntry = `INSERT INTO dbforum.audit_log(actor, action, target_type, target_id, forum_slug, details)
+				VALUES ($1, $2, $3, $4, $5, $6)`
+
+	// Параметры читают триггер dbforum.audit_row() и значение по умолчанию
+	// audit_log.request_id до конца транзакции.
+	setAuditContext = "SELECT set_config('dbforum.actor', $1, true), set_config('dbforum.request_id', $2, true)"
+
+	auditFilter = "WHERE ($1::text = '' OR actor = $1::citext) " +
+		"AND ($2::text = '' OR target_type = $2) " +
+		"AND ($3::text = '' OR target_id = $3) " +
+		"AND ($4::text = '' OR forum_slug = $4::citext) " +
+		"AND ($5::text = '' OR request_id = $5) "
+
+	selectAuditLog = "SELECT " + auditColumns + " FROM dbforum.audit_log " + auditFilter +
+		"AND ($6::bigint = 0 OR id > $6) " +
+		"ORDER BY id LIMIT $7"
+
+	selectAuditLogDesc = "SELECT " + auditColumns + " FROM dbforum.audit_log " + auditFilter +
+		"AND ($6::bigint = 0 OR id < $6) " +
+		"ORDER BY id DESC LIMIT $7"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Begin начинает транзакцию изменяющей операции и передаёт журналу аудита
+// её инициатора и идентификатор запроса.
+func Begin(db *pgx.ConnPool, actor models.Actor) (*pgx.Tx, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if actor.Nickname == "" && actor.RequestID == "" {
+		return tx, nil
+	}
+	if _, err := tx.Exec("setAuditContext", actor.Nickname, actor.RequestID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Record добавляет запись в журнал в транзакции изменяющей операции, чтобы
+// запись появлялась только вместе с самим изменением.
+func Record(tx *pgx.Tx, entry models.AuditEntry) error {
+	_, err := tx.Exec("insertAuditEntry",
+		entry.Actor,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Forum,
+		entry.Details)
+	return err
+}
+
+func (r *Repository) GetAuditLog(query models.AuditQuery) ([]models.AuditEntry, error) {
+	name := "selectAuditLog"
+	if query.Desc {
+		name = "selectAuditLogDesc"
+	}
+	rows, err := r.db.Query(name,
+		query.Actor,
+		query.TargetType,
+		query.TargetID,
+		query.Forum,
+		query.RequestID,
+		query.Since,
+		query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []models.AuditEntry
+	for rows.Next() {
+		e := models.AuditEntry{}
+		var before, after string
+		err := rows.Scan(
+			&e.ID,
+			&e.Actor,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&e.Forum,
+			&e.Details,
+			&before,
+			&after,
+			&e.RequestID,
+			&e.Created)
+		if err != nil {
+			return nil, err
+		}
+		if before != "" {
+			e.Before = json.RawMessage(before)
+		}
+		if after != "" {
+			e.After = json.RawMessage(after)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("insertAuditEntry", insertAuditEntry)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("setAuditContext", setAuditContext)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectAuditLog", selectAuditLog)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectAuditLogDesc", selectAuditLogDesc)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
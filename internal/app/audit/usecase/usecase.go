@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	"DBForum/internal/app/models"
+)
+
+type UseCase struct {
+	repo auditRepo.Repository
+}
+
+func NewUseCase(repo auditRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) GetAuditLog(query models.AuditQuery) ([]models.AuditEntry, error) {
+	entries, err := u.repo.GetAuditLog(query)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		return []models.AuditEntry{}, nil
+	}
+	return entries, nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	authUseCase "DBForum/internal/app/auth/usecase"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase authUseCase.UseCase
+}
+
+func NewHandler(useCase authUseCase.UseCase) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+	}
+}
+
+func (h *Handlers) Login(ctx *fasthttp.RequestCtx) {
+	var creds models.Credentials
+	if err := easyjson.Unmarshal(ctx.PostBody(), &creds); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	tokens, err := h.useCase.Login(creds)
+	if errors.Is(err, customErr.ErrInvalidCredentials) {
+		resp := map[string]string{
+			"message": "Invalid nickname or password",
+		}
+		httputils.RespondErr(ctx, http.StatusUnauthorized, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, tokens)
+}
+
+func (h *Handlers) Refresh(ctx *fasthttp.RequestCtx) {
+	var req models.RefreshRequest
+	if err := easyjson.Unmarshal(ctx.PostBody(), &req); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	tokens, err := h.useCase.Refresh(req.RefreshToken)
+	if errors.Is(err, customErr.ErrInvalidToken) {
+		resp := map[string]string{
+			"message": "Invalid or expired refresh token",
+		}
+		httputils.RespondErr(ctx, http.StatusUnauthorized, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, tokens)
+}
+
+func (h *Handlers) Logout(ctx *fasthttp.RequestCtx) {
+	var req models.RefreshRequest
+	if len(ctx.PostBody()) != 0 {
+		if err := easyjson.Unmarshal(ctx.PostBody(), &req); err != nil {
+			httputils.Respond(ctx, http.StatusInternalServerError, nil)
+			log.Println(err)
+			return
+		}
+	}
+
+	session, _ := middleware.CallerSession(ctx)
+	if err := h.useCase.Logout(session, req.All); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
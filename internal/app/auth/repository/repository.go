@@ -0,0 +1,104 @@
+package repository
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+	"time"
+)
+
+const (
+	insertSession = "INSERT INTO dbforum.sessions(nickname, refresh_hash, expires) VALUES ($1, $2, $3) RETURNING id"
+
+	rotateSession = `UPDATE dbforum.sessions SET refresh_hash = $1, expires = $2
+					WHERE refresh_hash = $3 AND revoked IS NULL AND expires > now()
+					RETURNING id, nickname`
+
+	checkSession = "SELECT 1 FROM dbforum.sessions WHERE id = $1 AND revoked IS NULL AND expires > now()"
+
+	revokeSession = "UPDATE dbforum.sessions SET revoked = now() WHERE id = $1 AND revoked IS NULL"
+
+	revokeUserSessions = "UPDATE dbforum.sessions SET revoked = now() WHERE nickname = $1 AND revoked IS NULL"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) CreateSession(nickname string, refreshHash string, expires time.Time) (uint64, error) {
+	var id uint64
+	err := r.db.QueryRow("insertSession", nickname, refreshHash, expires).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// RotateSession заменяет токен обновления активной сессии; старый токен
+// после этого недействителен.
+func (r *Repository) RotateSession(oldHash string, newHash string, expires time.Time) (models.Session, error) {
+	var session models.Session
+	err := r.db.QueryRow("rotateSession", newHash, expires, oldHash).Scan(&session.ID, &session.Nickname)
+	if err == pgx.ErrNoRows {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	if err != nil {
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+func (r *Repository) IsSessionActive(id uint64) (bool, error) {
+	rows, err := r.db.Query("checkSession", id)
+	if err != nil {
+		return false, err
+	}
+	active := rows.Next()
+	rows.Close()
+	return active, nil
+}
+
+func (r *Repository) RevokeSession(id uint64) error {
+	_, err := r.db.Exec("revokeSession", id)
+	return err
+}
+
+func (r *Repository) RevokeUserSessions(nickname string) error {
+	_, err := r.db.Exec("revokeUserSessions", nickname)
+	return err
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("insertSession", insertSession)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("rotateSession", rotateSession)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("checkSession", checkSession)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("revokeSession", revokeSession)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("revokeUserSessions", revokeUserSessions)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	authRepo "DBForum/internal/app/auth/repository"
+	"DBForum/internal/app/config"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	userRepo "DBForum/internal/app/user/repository"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"strings"
+	"time"
+)
+
+// claims содержимое токена доступа.
+type claims struct {
+	Subject string `json:"sub"`
+	Session uint64 `json:"sid"`
+	Expires int64  `json:"exp"`
+}
+
+type UseCase struct {
+	repo       authRepo.Repository
+	userRepo   userRepo.Repository
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewUseCase(repo authRepo.Repository, userRepo userRepo.Repository, conf config.Auth) *UseCase {
+	secret := []byte(conf.Secret)
+	if len(secret) == 0 {
+		log.Println("auth: DBFORUM_AUTH_SECRET is not set, tokens will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return &UseCase{
+		repo:       repo,
+		userRepo:   userRepo,
+		secret:     secret,
+		accessTTL:  conf.AccessTTL,
+		refreshTTL: conf.RefreshTTL,
+	}
+}
+
+// IsProtected проверяет, что от имени nickname нельзя действовать без
+// токена: у аккаунта есть пароль или он удалён.
+func (u *UseCase) IsProtected(nickname string) (bool, error) {
+	protected, err := u.userRepo.IsProtected(nickname)
+	if err != nil {
+		return false, err
+	}
+	return protected, nil
+}
+
+func (u *UseCase) Login(creds models.Credentials) (models.TokenPair, error) {
+	nickname, passwordHash, err := u.userRepo.GetPassword(creds.Nickname)
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		return models.TokenPair{}, customErr.ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	if passwordHash == "" {
+		return models.TokenPair{}, customErr.ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(creds.Password)) != nil {
+		return models.TokenPair{}, customErr.ErrInvalidCredentials
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	id, err := u.repo.CreateSession(nickname, hashToken(refreshToken), time.Now().Add(u.refreshTTL))
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	return u.tokenPair(models.Session{ID: id, Nickname: nickname}, refreshToken), nil
+}
+
+func (u *UseCase) Refresh(refreshToken string) (models.TokenPair, error) {
+	if refreshToken == "" {
+		return models.TokenPair{}, customErr.ErrInvalidToken
+	}
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	session, err := u.repo.RotateSession(hashToken(refreshToken), hashToken(newToken), time.Now().Add(u.refreshTTL))
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	return u.tokenPair(session, newToken), nil
+}
+
+func (u *UseCase) Logout(session models.Session, all bool) error {
+	if all {
+		return u.repo.RevokeUserSessions(session.Nickname)
+	}
+	return u.repo.RevokeSession(session.ID)
+}
+
+// Authenticate проверяет подпись и срок действия токена доступа, а также то,
+// что его сессия не отозвана.
+func (u *UseCase) Authenticate(accessToken string) (models.Session, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 2 {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, u.sign(parts[0])) {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	if time.Now().Unix() >= c.Expires {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	active, err := u.repo.IsSessionActive(c.Session)
+	if err != nil {
+		return models.Session{}, err
+	}
+	if !active {
+		return models.Session{}, customErr.ErrInvalidToken
+	}
+	return models.Session{ID: c.Session, Nickname: c.Subject}, nil
+}
+
+func (u *UseCase) tokenPair(session models.Session, refreshToken string) models.TokenPair {
+	payload, _ := json.Marshal(claims{
+		Subject: session.Nickname,
+		Session: session.ID,
+		Expires: time.Now().Add(u.accessTTL).Unix(),
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return models.TokenPair{
+		AccessToken:  encoded + "." + base64.RawURLEncoding.EncodeToString(u.sign(encoded)),
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(u.accessTTL / time.Second),
+	}
+}
+
+func (u *UseCase) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func newRefreshToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashToken токены обновления хранятся в базе только в виде хеша.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	banUseCase "DBForum/internal/app/ban/usecase"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase banUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase banUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+// Ban банит пользователя в форуме из параметра маршрута slug или глобально,
+// если параметра нет. Бан без until бессрочный.
+func (h *Handlers) Ban(ctx *fasthttp.RequestCtx) {
+	var ban models.Ban
+	if err := easyjson.Unmarshal(ctx.PostBody(), &ban); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	ban.Forum, _ = ctx.UserValue("slug").(string)
+	ban.BannedBy = middleware.Caller(ctx)
+
+	ban, err := h.useCase.Ban(ban, middleware.Actor(ctx))
+	if h.respondBanErr(ctx, ban, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusCreated, ban)
+}
+
+func (h *Handlers) Unban(ctx *fasthttp.RequestCtx) {
+	var ban models.Ban
+	if err := easyjson.Unmarshal(ctx.PostBody(), &ban); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	ban.Forum, _ = ctx.UserValue("slug").(string)
+
+	err := h.useCase.Unban(ban.Nickname, ban.Forum, middleware.Actor(ctx))
+	if h.respondBanErr(ctx, ban, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
+
+func (h *Handlers) GetBans(ctx *fasthttp.RequestCtx) {
+	forumSlug, _ := ctx.UserValue("slug").(string)
+	// since - идентификатор бана, после которого будут выводиться записи
+	// (бан с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+
+	var bans models.BanList
+	bans, err := h.useCase.GetBans(forumSlug, page.Limit, page.SinceID, page.Desc)
+	if h.respondBanErr(ctx, models.Ban{Forum: forumSlug}, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, bans)
+}
+
+func (h *Handlers) respondBanErr(ctx *fasthttp.RequestCtx, ban models.Ban, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + ban.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrForumNotFound) {
+		resp := map[string]string{
+			"message": "Can't find forum by slug: " + ban.Forum,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrBanNotFound) {
+		resp := map[string]string{
+			"message": "User is not banned: " + ban.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrInvalidBanPeriod) {
+		resp := map[string]string{
+			"message": "Ban end must be in the future",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+)
+
+const (
+	banColumns = "id, nickname, forum_slug, reason, banned_by, created, expires"
+
+	// Повторный бан заменяет предыдущий в том же форуме.
+	insertBan = `INSERT INTO dbforum.bans(nickname, forum_slug, reason, banned_by, expires)
+				VALUES ((SELECT nickname FROM dbforum.users WHERE nickname = $1), $2, $3, $4, $5)
+				ON CONFLICT (nickname, forum_slug) DO UPDATE SET
+					reason = EXCLUDED.reason,
+					banned_by = EXCLUDED.banned_by,
+					created = now(),
+					expires = EXCLUDED.expires
+				RETURNING ` + banColumns
+
+	deleteBan = "DELETE FROM dbforum.bans WHERE nickname = $1 AND forum_slug = $2"
+
+	selectBans = "SELECT " + banColumns + " FROM dbforum.bans " +
+		"WHERE forum_slug = $1 AND (expires IS NULL OR expires > now()) " +
+		"AND ($2::bigint = 0 OR id > $2) " +
+		"ORDER BY id LIMIT $3"
+
+	selectBansDesc = "SELECT " + banColumns + " FROM dbforum.bans " +
+		"WHERE forum_slug = $1 AND (expires IS NULL OR expires > now()) " +
+		"AND ($2::bigint = 0 OR id < $2) " +
+		"ORDER BY id DESC LIMIT $3"
+
+	// Глобальный бан (пустой forum_slug) действует во всех форумах.
+	selectActiveBan = "SELECT nickname, forum_slug, reason, expires FROM dbforum.bans " +
+		"WHERE nickname = $1 AND forum_slug IN ('', $2) " +
+		"AND (expires IS NULL OR expires > now()) " +
+		"ORDER BY forum_slug LIMIT 1"
+
+	selectBanForumSlug = "SELECT slug FROM dbforum.forum WHERE slug = $1"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// CheckBan возвращает *errors.BanError, если у пользователя есть действующий
+// бан в форуме forumSlug или глобальный. Используется внутри транзакций
+// других репозиториев.
+func CheckBan(tx *pgx.Tx, nickname string, forumSlug string) error {
+	rows, err := tx.Query("selectActiveBan", nickname, forumSlug)
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		rows.Close()
+		return nil
+	}
+	banErr := &customErr.BanError{}
+	err = rows.Scan(
+		&banErr.Nickname,
+		&banErr.Forum,
+		&banErr.Reason,
+		&banErr.Until)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	return banErr
+}
+
+// InsertBan сохраняет бан внутри транзакции вызывающего; форум должен
+// быть уже проверен.
+func InsertBan(tx *pgx.Tx, ban *models.Ban) error {
+	err := tx.QueryRow("insertBan", ban.Nickname, ban.Forum, ban.Reason, ban.BannedBy, ban.Until).Scan(
+		&ban.ID,
+		&ban.Nickname,
+		&ban.Forum,
+		&ban.Reason,
+		&ban.BannedBy,
+		&ban.Created,
+		&ban.Until)
+	if driverErr, ok := err.(pgx.PgError); ok {
+		if driverErr.Code == "23502" {
+			return customErr.ErrUserNotFound
+		}
+	}
+	return err
+}
+
+func (r *Repository) Ban(ban *models.Ban, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	if ban.Forum != "" {
+		rows, err := tx.Query("selectBanForumSlug", ban.Forum)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if !rows.Next() {
+			rows.Close()
+			_ = tx.Rollback()
+			return customErr.ErrForumNotFound
+		}
+		err = rows.Scan(&ban.Forum)
+		rows.Close()
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err = InsertBan(tx, ban); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Unban(nickname string, forumSlug string, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec("deleteBan", nickname, forumSlug)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback()
+		return customErr.ErrBanNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetBans(forumSlug string, limit int, since uint64, desc bool) ([]models.Ban, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	var rows *pgx.Rows
+	if forumSlug != "" {
+		rows, err = tx.Query("selectBanForumSlug", forumSlug)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if !rows.Next() {
+			rows.Close()
+			_ = tx.Rollback()
+			return nil, customErr.ErrForumNotFound
+		}
+		err = rows.Scan(&forumSlug)
+		rows.Close()
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	if desc {
+		rows, err = tx.Query("selectBansDesc", forumSlug, since, limit)
+	} else {
+		rows, err = tx.Query("selectBans", forumSlug, since, limit)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var bans []models.Ban
+	for rows.Next() {
+		b := models.Ban{}
+		err := rows.Scan(
+			&b.ID,
+			&b.Nickname,
+			&b.Forum,
+			&b.Reason,
+			&b.BannedBy,
+			&b.Created,
+			&b.Until)
+		if err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return nil, err
+		}
+		bans = append(bans, b)
+	}
+	rows.Close()
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return bans, nil
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("insertBan", insertBan)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteBan", deleteBan)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectBans", selectBans)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectBansDesc", selectBansDesc)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectActiveBan", selectActiveBan)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectBanForumSlug", selectBanForumSlug)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	banRepo "DBForum/internal/app/ban/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"time"
+)
+
+type UseCase struct {
+	repo banRepo.Repository
+}
+
+func NewUseCase(repo banRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) Ban(ban models.Ban, actor models.Actor) (models.Ban, error) {
+	if ban.Until != nil && !ban.Until.After(time.Now()) {
+		return models.Ban{}, customErr.ErrInvalidBanPeriod
+	}
+	if err := u.repo.Ban(&ban, actor); err != nil {
+		return models.Ban{}, err
+	}
+	return ban, nil
+}
+
+func (u *UseCase) Unban(nickname string, forumSlug string, actor models.Actor) error {
+	return u.repo.Unban(nickname, forumSlug, actor)
+}
+
+func (u *UseCase) GetBans(forumSlug string, limit int, since uint64, desc bool) ([]models.Ban, error) {
+	bans, err := u.repo.GetBans(forumSlug, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	if bans == nil {
+		return []models.Ban{}, nil
+	}
+	return bans, nil
+}
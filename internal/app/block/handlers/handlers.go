@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	blockUseCase "DBForum/internal/app/block/usecase"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase blockUseCase.UseCase
+}
+
+func NewHandler(useCase blockUseCase.UseCase) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+	}
+}
+
+// Block добавляет {"nickname": ...} в список блокировок пользователя из
+// маршрута. Блокировать можно только от своего имени.
+func (h *Handlers) Block(ctx *fasthttp.RequestCtx) {
+	nickname, block, ok := h.parseBlock(ctx)
+	if !ok {
+		return
+	}
+
+	block, err := h.useCase.Block(nickname, block)
+	if h.respondBlockErr(ctx, nickname, block, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, block)
+}
+
+func (h *Handlers) Unblock(ctx *fasthttp.RequestCtx) {
+	nickname, block, ok := h.parseBlock(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.useCase.Unblock(nickname, block.Nickname)
+	if h.respondBlockErr(ctx, nickname, block, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
+
+// GetBlocks список блокировок пользователя, доступен только ему самому.
+func (h *Handlers) GetBlocks(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	var blocks models.BlockList
+	blocks, err := h.useCase.GetBlocks(nickname, middleware.Actor(ctx))
+	if h.respondBlockErr(ctx, nickname, models.Block{}, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, blocks)
+}
+
+func (h *Handlers) parseBlock(ctx *fasthttp.RequestCtx) (string, models.Block, bool) {
+	var block models.Block
+	if err := easyjson.Unmarshal(ctx.PostBody(), &block); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return "", block, false
+	}
+	nickname, ok := middleware.BindCaller(ctx, ctx.UserValue("nickname").(string))
+	if !ok {
+		middleware.RespondAuthorMismatch(ctx, nickname)
+		return "", block, false
+	}
+	return nickname, block, true
+}
+
+func (h *Handlers) respondBlockErr(ctx *fasthttp.RequestCtx, nickname string, block models.Block, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't read blocks of another user: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) && block.Nickname == "" {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + nickname + " or " + block.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrSelfBlock) {
+		resp := map[string]string{
+			"message": "Can't block yourself",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrBlockNotFound) {
+		resp := map[string]string{
+			"message": nickname + " has not blocked " + block.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
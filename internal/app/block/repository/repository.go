@@ -0,0 +1,173 @@
+package repository
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+)
+
+const (
+	insertBlock = `INSERT INTO dbforum.user_blocks(nickname, blocked, kind)
+				VALUES ((SELECT nickname FROM dbforum.users WHERE nickname = $1),
+				        (SELECT nickname FROM dbforum.users WHERE nickname = $2), $3)
+				ON CONFLICT (nickname, blocked) DO UPDATE SET kind = EXCLUDED.kind
+				RETURNING blocked, kind, created`
+
+	selectBlockOwner = "SELECT nickname FROM dbforum.users WHERE nickname = $1"
+
+	selectBlocks = "SELECT blocked, kind, created FROM dbforum.user_blocks WHERE nickname = $1 ORDER BY created, blocked"
+
+	deleteBlock = "DELETE FROM dbforum.user_blocks WHERE nickname = $1 AND blocked = $2"
+
+	// Первый из nicknames, заблокировавший $1.
+	selectBlockedBy = "SELECT nickname FROM dbforum.user_blocks " +
+		"WHERE blocked = $1 AND nickname = ANY($2::citext[]) AND kind = 'block' LIMIT 1"
+
+	// Автор сообщения $1 заблокировал $2.
+	selectReplyBlocked = "SELECT EXISTS(SELECT 1 FROM dbforum.post AS p " +
+		"JOIN dbforum.user_blocks AS b ON b.nickname = p.author_nickname " +
+		"WHERE p.id = $1 AND b.blocked = $2 AND b.kind = 'block')"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Block блокирует blocked для nickname. Повторная блокировка меняет вид
+// блокировки.
+func (r *Repository) Block(nickname string, block *models.Block) error {
+	kind := "block"
+	if block.Mute {
+		kind = "mute"
+	}
+	err := r.db.QueryRow("insertBlock", nickname, block.Nickname, kind).Scan(&block.Nickname, &kind, &block.Created)
+	if driverErr, ok := err.(pgx.PgError); ok && driverErr.Code == "23502" {
+		return customErr.ErrUserNotFound
+	}
+	block.Mute = kind == "mute"
+	return err
+}
+
+func (r *Repository) GetBlocks(nickname string) ([]models.Block, error) {
+	var owner string
+	err := r.db.QueryRow("selectBlockOwner", nickname).Scan(&owner)
+	if err == pgx.ErrNoRows {
+		return nil, customErr.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query("selectBlocks", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var blocks []models.Block
+	for rows.Next() {
+		b := models.Block{}
+		var kind string
+		if err := rows.Scan(&b.Nickname, &kind, &b.Created); err != nil {
+			return nil, err
+		}
+		b.Mute = kind == "mute"
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+func (r *Repository) Unblock(nickname string, blocked string) error {
+	tag, err := r.db.Exec("deleteBlock", nickname, blocked)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return customErr.ErrBlockNotFound
+	}
+	return nil
+}
+
+// CheckBlocked возвращает ErrBlocked, если кто-то из nicknames заблокировал
+// nickname.
+func CheckBlocked(tx *pgx.Tx, nickname string, nicknames []string) error {
+	var blocker string
+	err := tx.QueryRow("selectBlockedBy", nickname, nicknames).Scan(&blocker)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return customErr.ErrBlocked
+}
+
+// GetBlocked никнеймы всех, кого nickname заблокировал или заглушил.
+func (r *Repository) GetBlocked(nickname string) ([]string, error) {
+	rows, err := r.db.Query("selectBlocks", nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var blocked []string
+	for rows.Next() {
+		var b models.Block
+		var kind string
+		if err := rows.Scan(&b.Nickname, &kind, &b.Created); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, b.Nickname)
+	}
+	return blocked, rows.Err()
+}
+
+// CheckReply возвращает ErrBlocked, если автор сообщения parent
+// заблокировал author.
+func CheckReply(tx *pgx.Tx, parent int, author string) error {
+	var blocked bool
+	if err := tx.QueryRow("selectReplyBlocked", parent, author).Scan(&blocked); err != nil {
+		return err
+	}
+	if blocked {
+		return customErr.ErrBlocked
+	}
+	return nil
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("insertBlock", insertBlock)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteBlock", deleteBlock)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectBlockOwner", selectBlockOwner)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectBlocks", selectBlocks)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectReplyBlocked", selectReplyBlocked)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectBlockedBy", selectBlockedBy)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	blockRepo "DBForum/internal/app/block/repository"
+	"DBForum/internal/app/config"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"strings"
+)
+
+type UseCase struct {
+	repo   blockRepo.Repository
+	policy config.BlockPolicy
+}
+
+func NewUseCase(repo blockRepo.Repository, policy config.BlockPolicy) *UseCase {
+	return &UseCase{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+func (u *UseCase) Block(nickname string, block models.Block) (models.Block, error) {
+	if strings.EqualFold(nickname, block.Nickname) {
+		return models.Block{}, customErr.ErrSelfBlock
+	}
+	if err := u.repo.Block(nickname, &block); err != nil {
+		return models.Block{}, err
+	}
+	return block, nil
+}
+
+func (u *UseCase) Unblock(nickname string, blocked string) error {
+	return u.repo.Unblock(nickname, blocked)
+}
+
+// GetBlocks список блокировок доступен только его владельцу.
+func (u *UseCase) GetBlocks(nickname string, actor models.Actor) ([]models.Block, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return nil, customErr.ErrForbidden
+	}
+	blocks, err := u.repo.GetBlocks(nickname)
+	if err != nil {
+		return nil, err
+	}
+	if blocks == nil {
+		return []models.Block{}, nil
+	}
+	return blocks, nil
+}
+
+// FilterPosts сворачивает или убирает из выдачи сообщения авторов,
+// заблокированных или заглушённых viewer. При удалении страница становится
+// короче limit.
+func (u *UseCase) FilterPosts(viewer string, posts []models.Post) ([]models.Post, error) {
+	hidden, err := u.hidden(viewer)
+	if err != nil || len(hidden) == 0 {
+		return posts, err
+	}
+	filtered := posts[:0]
+	for _, post := range posts {
+		if !hidden[strings.ToLower(post.Author)] {
+			filtered = append(filtered, post)
+			continue
+		}
+		if u.policy.Mode == config.BlockOmit {
+			continue
+		}
+		post.Message = ""
+		post.Collapsed = true
+		filtered = append(filtered, post)
+	}
+	return filtered, nil
+}
+
+// FilterThreads как FilterPosts для списка веток.
+func (u *UseCase) FilterThreads(viewer string, threads []models.Thread) ([]models.Thread, error) {
+	hidden, err := u.hidden(viewer)
+	if err != nil || len(hidden) == 0 {
+		return threads, err
+	}
+	filtered := threads[:0]
+	for _, thread := range threads {
+		if !hidden[strings.ToLower(thread.Author)] {
+			filtered = append(filtered, thread)
+			continue
+		}
+		if u.policy.Mode == config.BlockOmit {
+			continue
+		}
+		thread.Message = ""
+		thread.Collapsed = true
+		filtered = append(filtered, thread)
+	}
+	return filtered, nil
+}
+
+func (u *UseCase) hidden(viewer string) (map[string]bool, error) {
+	if viewer == "" {
+		return nil, nil
+	}
+	blocked, err := u.repo.GetBlocked(viewer)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make(map[string]bool, len(blocked))
+	for _, nickname := range blocked {
+		hidden[strings.ToLower(nickname)] = true
+	}
+	return hidden, nil
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VotePolicy ограничения на голосование за ветки обсуждения.
+// Нулевые значения отключают соответствующую проверку.
+type VotePolicy struct {
+	// Запрещает голосовать за собственные ветки.
+	RejectSelfVotes bool
+	// Минимальный возраст аккаунта, после которого разрешено голосовать.
+	MinAccountAge time.Duration
+	// Минимальное количество сообщений пользователя.
+	MinPosts int
+	// Максимальное количество изменений голоса за окно ChangesWindow.
+	MaxChanges    int
+	ChangesWindow time.Duration
+}
+
+// EditPolicy ограничения на правку сообщений и веток.
+type EditPolicy struct {
+	// Время после создания, в течение которого автор может править запись;
+	// после него править могут только модераторы. Ноль снимает ограничение.
+	Window time.Duration
+}
+
+// FilterPolicy встроенные проверки содержимого новых сообщений и веток.
+// Запрещённые слова задаются для каждого форума отдельно.
+type FilterPolicy struct {
+	// Пользователи, зарегистрированные менее NewUserAge назад, могут
+	// публиковать не больше MaxLinks ссылок в одной записи. Нулевой
+	// NewUserAge отключает проверку.
+	NewUserAge time.Duration
+	MaxLinks   int
+	// Повтор автором своего текста в течение окна отклоняется. Ноль
+	// отключает проверку.
+	DuplicateWindow time.Duration
+}
+
+// Rate не больше Count запросов за Per с накоплением до Count.
+// Нулевой Count снимает ограничение.
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+// RateLimit ограничения частоты запросов записи по классам маршрутов
+// (post, thread, vote, profile). Forums переопределяет ограничения
+// классов для отдельных форумов; ключи - slug в нижнем регистре.
+type RateLimit struct {
+	Classes map[string]Rate
+	Forums  map[string]map[string]Rate
+}
+
+// BlockPolicy как выводятся записи заблокированных вызывающим пользователей:
+// collapse - без текста с флагом collapsed, omit - не выводятся.
+type BlockPolicy struct {
+	Mode string
+}
+
+const (
+	BlockCollapse = "collapse"
+	BlockOmit     = "omit"
+)
+
+// RenamePolicy смена никнейма. Прежний никнейм закреплён за владельцем на
+// Reservation: другие не могут его занять, сам владелец может вернуть.
+type RenamePolicy struct {
+	Reservation time.Duration
+}
+
+// Avatar хранилище загруженных аватаров.
+type Avatar struct {
+	// Каталог на диске, из которого файлы отдаются по адресам /avatars/...
+	Dir     string
+	MaxSize int
+}
+
+// Page размер страницы списков: Default при отсутствии limit, Max - наибольший
+// допустимый limit.
+type Page struct {
+	Default int
+	Max     int
+}
+
+type Search struct {
+	// Конфигурация текстового поиска Postgres (simple, english, russian, ...).
+	Language string
+}
+
+type Auth struct {
+	// Ключ подписи токенов доступа. Если не задан, генерируется при запуске,
+	// и выданные ранее токены перестают действовать после перезапуска.
+	Secret     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Требовать токен для всех операций записи.
+	Required bool
+}
+
+type Config struct {
+	Vote   VotePolicy
+	Edit   EditPolicy
+	Filter FilterPolicy
+	Rate   RateLimit
+	Block  BlockPolicy
+	Avatar Avatar
+	Rename RenamePolicy
+	Page   Page
+	Search Search
+	Auth   Auth
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Vote: VotePolicy{
+			RejectSelfVotes: envBool("DBFORUM_VOTE_REJECT_SELF", false),
+			MinAccountAge:   envDuration("DBFORUM_VOTE_MIN_ACCOUNT_AGE", 0),
+			MinPosts:        envInt("DBFORUM_VOTE_MIN_POSTS", 0),
+			MaxChanges:      envInt("DBFORUM_VOTE_MAX_CHANGES", 0),
+			ChangesWindow:   envDuration("DBFORUM_VOTE_CHANGES_WINDOW", time.Hour),
+		},
+		Edit: EditPolicy{
+			Window: envDuration("DBFORUM_EDIT_WINDOW", 0),
+		},
+		Filter: FilterPolicy{
+			NewUserAge:      envDuration("DBFORUM_FILTER_NEW_USER_AGE", 0),
+			MaxLinks:        envInt("DBFORUM_FILTER_MAX_LINKS", 0),
+			DuplicateWindow: envDuration("DBFORUM_FILTER_DUPLICATE_WINDOW", 0),
+		},
+		Rate: RateLimit{
+			Classes: map[string]Rate{
+				"post":    envRate("DBFORUM_RATE_POST"),
+				"thread":  envRate("DBFORUM_RATE_THREAD"),
+				"vote":    envRate("DBFORUM_RATE_VOTE"),
+				"profile": envRate("DBFORUM_RATE_PROFILE"),
+			},
+			Forums: envForumRates("DBFORUM_RATE_FORUMS"),
+		},
+		Block: BlockPolicy{
+			Mode: envChoice("DBFORUM_BLOCK_MODE", BlockCollapse, BlockCollapse, BlockOmit),
+		},
+		Avatar: Avatar{
+			Dir:     envString("DBFORUM_AVATAR_DIR", "avatars"),
+			MaxSize: envInt("DBFORUM_AVATAR_MAX_SIZE", 1<<20),
+		},
+		Rename: RenamePolicy{
+			Reservation: envDuration("DBFORUM_RENAME_RESERVATION", 30*24*time.Hour),
+		},
+		Page: pageLimits(),
+		Search: Search{
+			Language: envString("DBFORUM_SEARCH_LANGUAGE", "simple"),
+		},
+		Auth: Auth{
+			Secret:     envString("DBFORUM_AUTH_SECRET", ""),
+			AccessTTL:  envDuration("DBFORUM_AUTH_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL: envDuration("DBFORUM_AUTH_REFRESH_TTL", 30*24*time.Hour),
+			Required:   envBool("DBFORUM_AUTH_REQUIRED", false),
+		},
+	}
+}
+
+func envString(key string, def string) string {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return def
+	}
+	return value
+}
+
+// envChoice читает одно из значений choices.
+func envChoice(key string, def string, choices ...string) string {
+	value := envString(key, def)
+	for _, choice := range choices {
+		if value == choice {
+			return value
+		}
+	}
+	log.Printf("config: invalid %s=%q, using %v", key, value, def)
+	return def
+}
+
+func envBool(key string, def bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %v", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// pageLimits размер страницы списков. Max меньше 1 и Default вне 1..Max
+// заменяются значениями по умолчанию, Default - не больше Max.
+func pageLimits() Page {
+	page := Page{
+		Default: envInt("DBFORUM_PAGE_DEFAULT_LIMIT", 100),
+		Max:     envInt("DBFORUM_PAGE_MAX_LIMIT", 1000),
+	}
+	if page.Max < 1 {
+		log.Printf("config: invalid %s=%q, using %v", "DBFORUM_PAGE_MAX_LIMIT", strconv.Itoa(page.Max), 1000)
+		page.Max = 1000
+	}
+	if page.Default < 1 || page.Default > page.Max {
+		def := 100
+		if def > page.Max {
+			def = page.Max
+		}
+		log.Printf("config: invalid %s=%q, using %v", "DBFORUM_PAGE_DEFAULT_LIMIT", strconv.Itoa(page.Default), def)
+		page.Default = def
+	}
+	return page
+}
+
+func envInt(key string, def int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %v", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %v", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// envRate читает ограничение вида 30/1m.
+func envRate(key string) Rate {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return Rate{}
+	}
+	rate, err := parseRate(value)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, rate limit disabled", key, value)
+		return Rate{}
+	}
+	return rate
+}
+
+// envForumRates читает ограничения форумов вида news:post=5/1m,news:thread=1/1h.
+func envForumRates(key string) map[string]map[string]Rate {
+	forums := map[string]map[string]Rate{}
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return forums
+	}
+	for _, item := range strings.Split(value, ",") {
+		target, limit, ok := cut(strings.TrimSpace(item), "=")
+		forum, class, ok2 := cut(target, ":")
+		rate, err := parseRate(limit)
+		if !ok || !ok2 || forum == "" || class == "" || err != nil {
+			log.Printf("config: invalid %s item %q, skipped", key, item)
+			continue
+		}
+		forum = strings.ToLower(forum)
+		if forums[forum] == nil {
+			forums[forum] = map[string]Rate{}
+		}
+		forums[forum][class] = rate
+	}
+	return forums
+}
+
+func parseRate(value string) (Rate, error) {
+	count, per, ok := cut(value, "/")
+	if !ok {
+		return Rate{}, strconv.ErrSyntax
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return Rate{}, strconv.ErrSyntax
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Rate{}, strconv.ErrSyntax
+	}
+	return Rate{Count: n, Per: d}, nil
+}
+
+func cut(s string, sep string) (string, string, bool) {
+	parts := strings.SplitN(s, sep, 2)
+	if len(parts) < 2 {
+		return s, "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	conversationUseCase "DBForum/internal/app/conversation/usecase"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type Handlers struct {
+	useCase conversationUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase conversationUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+// Create начинает переписку автора с участниками members.
+func (h *Handlers) Create(ctx *fasthttp.RequestCtx) {
+	var conv models.Conversation
+	if err := easyjson.Unmarshal(ctx.PostBody(), &conv); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	var ok bool
+	if conv.Author, ok = middleware.BindCaller(ctx, conv.Author); !ok {
+		middleware.RespondAuthorMismatch(ctx, conv.Author)
+		return
+	}
+
+	created, err := h.useCase.CreateConversation(conv)
+	if h.respondConversationErr(ctx, "", err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusCreated, created)
+}
+
+func (h *Handlers) Send(ctx *fasthttp.RequestCtx) {
+	msg, ok := h.parseMessage(ctx)
+	if !ok {
+		return
+	}
+
+	sent, err := h.useCase.SendMessage(msg)
+	if h.respondConversationErr(ctx, ctx.UserValue("id").(string), err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusCreated, sent)
+}
+
+// Leave выводит вызывающего из переписки.
+func (h *Handlers) Leave(ctx *fasthttp.RequestCtx) {
+	msg, ok := h.parseMessage(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.useCase.Leave(msg.Conversation, msg.Author)
+	if h.respondConversationErr(ctx, ctx.UserValue("id").(string), err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
+
+func (h *Handlers) GetConversations(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	// since - идентификатор переписки, после которой будут выводиться записи
+	// (переписка с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+
+	var convs models.ConversationList
+	convs, err := h.useCase.GetConversations(nickname, page.Limit, page.SinceID, middleware.Actor(ctx))
+	if h.respondConversationErr(ctx, "", err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, convs)
+}
+
+// GetMessages история переписки. Показанные сообщения становятся
+// прочитанными.
+func (h *Handlers) GetMessages(ctx *fasthttp.RequestCtx) {
+	id := ctx.UserValue("id").(string)
+	conversationID, _ := strconv.ParseUint(id, 10, 64)
+	// since - идентификатор сообщения, после которого будут выводиться записи
+	// (сообщение с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+
+	var messages models.PrivateMessageList
+	messages, err := h.useCase.GetMessages(conversationID, page.Limit, page.SinceID, page.Desc, middleware.Actor(ctx))
+	if h.respondConversationErr(ctx, id, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, messages)
+}
+
+func (h *Handlers) parseMessage(ctx *fasthttp.RequestCtx) (models.PrivateMessage, bool) {
+	var msg models.PrivateMessage
+	if len(ctx.PostBody()) > 0 {
+		if err := easyjson.Unmarshal(ctx.PostBody(), &msg); err != nil {
+			httputils.Respond(ctx, http.StatusInternalServerError, nil)
+			log.Println(err)
+			return msg, false
+		}
+	}
+	var ok bool
+	if msg.Author, ok = middleware.BindCaller(ctx, msg.Author); !ok {
+		middleware.RespondAuthorMismatch(ctx, msg.Author)
+		return msg, false
+	}
+	msg.Conversation, _ = strconv.ParseUint(ctx.UserValue("id").(string), 10, 64)
+	return msg, true
+}
+
+func (h *Handlers) respondConversationErr(ctx *fasthttp.RequestCtx, id string, err error) bool {
+	if err == nil {
+		return false
+	}
+	var notFound *customErr.UserNotFoundError
+	if errors.As(err, &notFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + notFound.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrConversationNotFound) {
+		resp := map[string]string{
+			"message": "Can't find conversation by id: " + id,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrForbidden) || errors.Is(err, customErr.ErrNotConversationMember) {
+		resp := map[string]string{
+			"message": "Not a member of the conversation",
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrBlocked) {
+		resp := map[string]string{
+			"code":    "blocked",
+			"message": "A participant has blocked the author",
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrNotEnoughParticipants) {
+		resp := map[string]string{
+			"message": "A conversation needs at least one member besides the author",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
@@ -0,0 +1,380 @@
+package repository
+
+import (
+	blockRepo "DBForum/internal/app/block/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+	"strings"
+)
+
+const (
+	selectParticipant = "SELECT nickname FROM dbforum.users WHERE nickname = $1"
+
+	insertConversation = `INSERT INTO dbforum.conversations(title, created_by) VALUES ($1, $2)
+				RETURNING id, created`
+
+	insertConversationMember = "INSERT INTO dbforum.conversation_members(conversation_id, nickname) VALUES ($1, $2)"
+
+	selectConversation = "SELECT id FROM dbforum.conversations WHERE id = $1"
+
+	selectActiveMember = "SELECT last_read FROM dbforum.conversation_members " +
+		"WHERE conversation_id = $1 AND nickname = $2 AND left_at IS NULL"
+
+	insertPrivateMessage = `INSERT INTO dbforum.private_messages(conversation_id, author_nickname, message)
+				VALUES ($1, $2, $3)
+				RETURNING id, author_nickname, created`
+
+	updateLastMessage = "UPDATE dbforum.conversations SET last_message = $2 WHERE id = $1"
+
+	updateLastRead = "UPDATE dbforum.conversation_members SET last_read = GREATEST(last_read, $3) " +
+		"WHERE conversation_id = $1 AND nickname = $2"
+
+	selectActiveMembers = "SELECT nickname FROM dbforum.conversation_members " +
+		"WHERE conversation_id = $1 AND nickname <> $2 AND left_at IS NULL"
+
+	leaveConversation = "UPDATE dbforum.conversation_members SET left_at = now() " +
+		"WHERE conversation_id = $1 AND nickname = $2 AND left_at IS NULL"
+
+	// Сначала переписки с самым свежим сообщением. since - идентификатор
+	// переписки, после которой выводятся записи.
+	selectConversations = `SELECT c.id, c.title, c.created_by, c.created,
+					ARRAY(SELECT cm.nickname::text FROM dbforum.conversation_members AS cm
+					      WHERE cm.conversation_id = c.id AND cm.left_at IS NULL ORDER BY cm.joined, cm.nickname),
+					COALESCE(pm.id, 0), COALESCE(pm.author_nickname, ''), COALESCE(pm.message, ''),
+					COALESCE(pm.created, c.created),
+					(SELECT COUNT(*) FROM dbforum.private_messages AS u
+					 WHERE u.conversation_id = c.id AND u.id > m.last_read)
+				FROM dbforum.conversation_members AS m
+				JOIN dbforum.conversations AS c ON c.id = m.conversation_id
+				LEFT JOIN dbforum.private_messages AS pm ON pm.id = c.last_message
+				WHERE m.nickname = $1 AND m.left_at IS NULL
+				  AND ($2::bigint = 0 OR (c.last_message, c.id) <
+				      (SELECT last_message, id FROM dbforum.conversations WHERE id = $2))
+				ORDER BY c.last_message DESC, c.id DESC
+				LIMIT $3`
+
+	selectPrivateMessages = "SELECT id, conversation_id, author_nickname, message, created " +
+		"FROM dbforum.private_messages " +
+		"WHERE conversation_id = $1 AND ($2::bigint = 0 OR id > $2) ORDER BY id LIMIT $3"
+
+	selectPrivateMessagesDesc = "SELECT id, conversation_id, author_nickname, message, created " +
+		"FROM dbforum.private_messages " +
+		"WHERE conversation_id = $1 AND ($2::bigint = 0 OR id < $2) ORDER BY id DESC LIMIT $3"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// CreateConversation создаёт переписку автора с участниками conv.Members.
+// Никнеймы приводятся к записанным в базе, повторы отбрасываются. Переписку
+// нельзя начать с пользователем, заблокировавшим автора.
+func (r *Repository) CreateConversation(conv *models.Conversation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if conv.Author, err = participantTx(tx, conv.Author); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	members := []string{conv.Author}
+	seen := map[string]bool{strings.ToLower(conv.Author): true}
+	for _, member := range conv.Members {
+		nickname, err := participantTx(tx, member)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if seen[strings.ToLower(nickname)] {
+			continue
+		}
+		seen[strings.ToLower(nickname)] = true
+		members = append(members, nickname)
+	}
+	if len(members) < 2 {
+		_ = tx.Rollback()
+		return customErr.ErrNotEnoughParticipants
+	}
+	if err = blockRepo.CheckBlocked(tx, conv.Author, members[1:]); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = tx.QueryRow("insertConversation", conv.Title, conv.Author).Scan(&conv.ID, &conv.Created); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for _, member := range members {
+		if _, err = tx.Exec("insertConversationMember", conv.ID, member); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	conv.Members = members
+	return tx.Commit()
+}
+
+// SendMessage добавляет сообщение текущего участника в переписку. Писать
+// нельзя, если кто-то из участников заблокировал автора.
+func (r *Repository) SendMessage(msg *models.PrivateMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = memberTx(tx, msg.Conversation, msg.Author); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	members, err := activeMembersTx(tx, msg.Conversation, msg.Author)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = blockRepo.CheckBlocked(tx, msg.Author, members); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	err = tx.QueryRow("insertPrivateMessage", msg.Conversation, msg.Author, msg.Message).
+		Scan(&msg.ID, &msg.Author, &msg.Created)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec("updateLastMessage", msg.Conversation, msg.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	// Своё сообщение прочитано.
+	if _, err = tx.Exec("updateLastRead", msg.Conversation, msg.Author, msg.ID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) GetConversations(nickname string, limit int, since uint64) ([]models.Conversation, error) {
+	var user string
+	err := r.db.QueryRow("selectParticipant", nickname).Scan(&user)
+	if err == pgx.ErrNoRows {
+		return nil, &customErr.UserNotFoundError{Nickname: nickname}
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query("selectConversations", user, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var convs []models.Conversation
+	for rows.Next() {
+		c := models.Conversation{}
+		last := &models.PrivateMessage{}
+		err := rows.Scan(
+			&c.ID,
+			&c.Title,
+			&c.Author,
+			&c.Created,
+			&c.Members,
+			&last.ID,
+			&last.Author,
+			&last.Message,
+			&last.Created,
+			&c.Unread)
+		if err != nil {
+			return nil, err
+		}
+		if last.ID != 0 {
+			last.Conversation = c.ID
+			c.LastMessage = last
+		}
+		convs = append(convs, c)
+	}
+	return convs, rows.Err()
+}
+
+// GetMessages история переписки для текущего участника. Показанные
+// сообщения отмечаются прочитанными.
+func (r *Repository) GetMessages(conversationID uint64, nickname string, limit int, since uint64, desc bool) ([]models.PrivateMessage, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err = memberTx(tx, conversationID, nickname); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	name := "selectPrivateMessages"
+	if desc {
+		name = "selectPrivateMessagesDesc"
+	}
+	rows, err := tx.Query(name, conversationID, since, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var messages []models.PrivateMessage
+	var lastRead uint64
+	for rows.Next() {
+		m := models.PrivateMessage{}
+		if err := rows.Scan(&m.ID, &m.Conversation, &m.Author, &m.Message, &m.Created); err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if m.ID > lastRead {
+			lastRead = m.ID
+		}
+		messages = append(messages, m)
+	}
+	rows.Close()
+	if lastRead > 0 {
+		if _, err = tx.Exec("updateLastRead", conversationID, nickname, lastRead); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Leave выводит участника из переписки. История остаётся у остальных.
+func (r *Repository) Leave(conversationID uint64, nickname string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = memberTx(tx, conversationID, nickname); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec("leaveConversation", conversationID, nickname); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func participantTx(tx *pgx.Tx, nickname string) (string, error) {
+	var user string
+	err := tx.QueryRow("selectParticipant", nickname).Scan(&user)
+	if err == pgx.ErrNoRows {
+		return "", &customErr.UserNotFoundError{Nickname: nickname}
+	}
+	return user, err
+}
+
+// activeMembersTx остальные текущие участники переписки.
+func activeMembersTx(tx *pgx.Tx, conversationID uint64, nickname string) ([]string, error) {
+	rows, err := tx.Query("selectActiveMembers", conversationID, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var members []string
+	for rows.Next() {
+		var member string
+		if err := rows.Scan(&member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// memberTx проверяет, что nickname - текущий участник переписки.
+func memberTx(tx *pgx.Tx, conversationID uint64, nickname string) error {
+	var lastRead uint64
+	err := tx.QueryRow("selectActiveMember", conversationID, nickname).Scan(&lastRead)
+	if err != pgx.ErrNoRows {
+		return err
+	}
+	var id uint64
+	err = tx.QueryRow("selectConversation", conversationID).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return customErr.ErrConversationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return customErr.ErrNotConversationMember
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectParticipant", selectParticipant)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertConversation", insertConversation)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertConversationMember", insertConversationMember)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectConversation", selectConversation)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectActiveMember", selectActiveMember)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertPrivateMessage", insertPrivateMessage)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("updateLastMessage", updateLastMessage)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("updateLastRead", updateLastRead)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectActiveMembers", selectActiveMembers)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("leaveConversation", leaveConversation)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectConversations", selectConversations)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectPrivateMessages", selectPrivateMessages)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectPrivateMessagesDesc", selectPrivateMessagesDesc)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
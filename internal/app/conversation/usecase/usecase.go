@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	conversationRepo "DBForum/internal/app/conversation/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"strings"
+)
+
+type UseCase struct {
+	repo conversationRepo.Repository
+}
+
+func NewUseCase(repo conversationRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) CreateConversation(conv models.Conversation) (models.Conversation, error) {
+	if err := u.repo.CreateConversation(&conv); err != nil {
+		return models.Conversation{}, err
+	}
+	return conv, nil
+}
+
+func (u *UseCase) SendMessage(msg models.PrivateMessage) (models.PrivateMessage, error) {
+	if err := u.repo.SendMessage(&msg); err != nil {
+		return models.PrivateMessage{}, err
+	}
+	return msg, nil
+}
+
+// GetConversations список переписок доступен только самому пользователю.
+func (u *UseCase) GetConversations(nickname string, limit int, since uint64, actor models.Actor) ([]models.Conversation, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return nil, customErr.ErrForbidden
+	}
+	convs, err := u.repo.GetConversations(nickname, limit, since)
+	if err != nil {
+		return nil, err
+	}
+	if convs == nil {
+		return []models.Conversation{}, nil
+	}
+	return convs, nil
+}
+
+// GetMessages история доступна текущим участникам переписки.
+func (u *UseCase) GetMessages(conversationID uint64, limit int, since uint64, desc bool, actor models.Actor) ([]models.PrivateMessage, error) {
+	messages, err := u.repo.GetMessages(conversationID, actor.Nickname, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		return []models.PrivateMessage{}, nil
+	}
+	return messages, nil
+}
+
+func (u *UseCase) Leave(conversationID uint64, nickname string) error {
+	return u.repo.Leave(conversationID, nickname)
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"DBForum/internal/app/config"
 	"github.com/jackc/pgx"
 	_ "github.com/jackc/pgx/stdlib"
 )
@@ -9,12 +10,15 @@ type Postgres struct {
 	db *pgx.ConnPool
 }
 
-func NewPostgres() (*Postgres, error) {
+func NewPostgres(appConf *config.Config) (*Postgres, error) {
 	conf := pgx.ConnConfig{
 		User:                 "postgres",
 		Database:             "postgres",
 		Password:             "admin",
 		PreferSimpleProtocol: false,
+		RuntimeParams: map[string]string{
+			"dbforum.search_language": appConf.Search.Language,
+		},
 	}
 
 	poolConf := pgx.ConnPoolConfig{
@@ -1,6 +1,9 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrDuplicate      = errors.New("duplicate")
@@ -10,4 +13,98 @@ var (
 	ErrForumNotFound  = errors.New("forum not found")
 	ErrThreadNotFound = errors.New("thread not found")
 	ErrPostNotFound   = errors.New("post not found")
+
+	ErrSelfVote        = errors.New("self vote")
+	ErrVoteNotAllowed  = errors.New("vote not allowed")
+	ErrVoteRateLimited = errors.New("vote rate limited")
+
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrUnknownSort        = errors.New("unknown sort")
+	ErrUnknownUsersFilter = errors.New("unknown users filter")
+
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrForbidden          = errors.New("forbidden")
+	ErrEditWindowExpired  = errors.New("edit window expired")
+
+	ErrUnknownRole  = errors.New("unknown role")
+	ErrRoleNotFound = errors.New("role not found")
+
+	ErrUserBanned       = errors.New("user banned")
+	ErrBanNotFound      = errors.New("ban not found")
+	ErrInvalidBanPeriod = errors.New("invalid ban period")
+
+	ErrReportExists            = errors.New("report exists")
+	ErrReportNotFound          = errors.New("report not found")
+	ErrUnknownModerationAction = errors.New("unknown moderation action")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	ErrContentRejected     = errors.New("content rejected")
+	ErrUnknownFilterAction = errors.New("unknown filter action")
+	ErrInvalidFilterWord   = errors.New("invalid filter word")
+	ErrFilterWordNotFound  = errors.New("filter word not found")
+
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	ErrSelfBlock             = errors.New("self block")
+	ErrBlockNotFound         = errors.New("block not found")
+	ErrBlocked               = errors.New("blocked")
+	ErrConversationNotFound  = errors.New("conversation not found")
+	ErrNotEnoughParticipants = errors.New("not enough participants")
+	ErrNotConversationMember = errors.New("not a conversation member")
+
+	ErrAvatarTooLarge = errors.New("avatar too large")
+	ErrAvatarType     = errors.New("unsupported avatar type")
+	ErrInvalidWebsite = errors.New("invalid website")
+
+	ErrInvalidNickname  = errors.New("invalid nickname")
+	ErrNicknameTaken    = errors.New("nickname taken")
+	ErrNicknameReserved = errors.New("nickname reserved")
+	ErrEmailReserved    = errors.New("email reserved")
 )
+
+// BanError действующий бан, из-за которого отклонена операция.
+type BanError struct {
+	Nickname string
+	Forum    string
+	Reason   string
+	Until    *time.Time
+}
+
+func (e *BanError) Error() string {
+	return "user " + e.Nickname + " banned"
+}
+
+func (e *BanError) Is(target error) bool {
+	return target == ErrUserBanned
+}
+
+// UserNotFoundError ненайденный пользователь из нескольких названных в
+// запросе.
+type UserNotFoundError struct {
+	Nickname string
+}
+
+func (e *UserNotFoundError) Error() string {
+	return "user " + e.Nickname + " not found"
+}
+
+func (e *UserNotFoundError) Is(target error) bool {
+	return target == ErrUserNotFound
+}
+
+// ContentError запись, отклонённая фильтром содержимого. Index - номер
+// записи в пакете, начиная с нуля.
+type ContentError struct {
+	Index  int
+	Reason string
+}
+
+func (e *ContentError) Error() string {
+	return "content rejected: " + e.Reason
+}
+
+func (e *ContentError) Is(target error) bool {
+	return target == ErrContentRejected
+}
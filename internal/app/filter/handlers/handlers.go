@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	filterUseCase "DBForum/internal/app/filter/usecase"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase filterUseCase.UseCase
+}
+
+func NewHandler(useCase filterUseCase.UseCase) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+	}
+}
+
+func (h *Handlers) GetWords(ctx *fasthttp.RequestCtx) {
+	forumSlug := ctx.UserValue("slug").(string)
+
+	var filters models.WordFilterList
+	filters, err := h.useCase.GetWordFilters(forumSlug)
+	if h.respondFilterErr(ctx, models.WordFilter{Forum: forumSlug}, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, filters)
+}
+
+// SetWord добавляет запрещённое слово в список форума или меняет действие
+// для уже добавленного.
+func (h *Handlers) SetWord(ctx *fasthttp.RequestCtx) {
+	var filter models.WordFilter
+	if err := easyjson.Unmarshal(ctx.PostBody(), &filter); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	filter.Forum = ctx.UserValue("slug").(string)
+
+	saved, err := h.useCase.SetWordFilter(filter, middleware.Actor(ctx))
+	if h.respondFilterErr(ctx, filter, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, saved)
+}
+
+func (h *Handlers) DeleteWord(ctx *fasthttp.RequestCtx) {
+	var filter models.WordFilter
+	if err := easyjson.Unmarshal(ctx.PostBody(), &filter); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	filter.Forum = ctx.UserValue("slug").(string)
+
+	err := h.useCase.DeleteWordFilter(filter.Forum, filter.Word, middleware.Actor(ctx))
+	if h.respondFilterErr(ctx, filter, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
+
+func (h *Handlers) respondFilterErr(ctx *fasthttp.RequestCtx, filter models.WordFilter, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrForumNotFound) {
+		resp := map[string]string{
+			"message": "Can't find forum by slug: " + filter.Forum,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrFilterWordNotFound) {
+		resp := map[string]string{
+			"message": "Word " + filter.Word + " is not filtered in forum " + filter.Forum,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrUnknownFilterAction) {
+		resp := map[string]string{
+			"message": "Unknown filter action: " + filter.Action,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrInvalidFilterWord) {
+		resp := map[string]string{
+			"message": "Filter word must be a single word: " + filter.Word,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+	"time"
+)
+
+const (
+	selectWordFilters = "SELECT forum_slug, word, action, created FROM dbforum.forum_word_filters " +
+		"WHERE forum_slug = $1 ORDER BY word"
+
+	selectFilterForumSlug = "SELECT slug FROM dbforum.forum WHERE slug = $1"
+
+	upsertWordFilter = `INSERT INTO dbforum.forum_word_filters(forum_slug, word, action)
+				VALUES ((SELECT slug FROM dbforum.forum WHERE slug = $1), $2, $3)
+				ON CONFLICT (forum_slug, word) DO UPDATE SET action = EXCLUDED.action
+				RETURNING forum_slug, word, action, created`
+
+	deleteWordFilter = "DELETE FROM dbforum.forum_word_filters WHERE forum_slug = $1 AND word = $2"
+
+	selectAuthorCreated = "SELECT created FROM dbforum.users WHERE nickname = $1"
+
+	selectRecentPost = "SELECT EXISTS(SELECT 1 FROM dbforum.post " +
+		"WHERE author_nickname = $1 AND message = $2 AND created > $3)"
+
+	selectRecentThread = "SELECT EXISTS(SELECT 1 FROM dbforum.thread " +
+		"WHERE author_nickname = $1 AND message = $2 AND created > $3)"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GetWordFilters запрещённые слова форума. Для несуществующего форума
+// возвращается пустой список.
+func (r *Repository) GetWordFilters(forumSlug string) ([]models.WordFilter, error) {
+	rows, err := r.db.Query("selectWordFilters", forumSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var filters []models.WordFilter
+	for rows.Next() {
+		f := models.WordFilter{}
+		if err := rows.Scan(&f.Forum, &f.Word, &f.Action, &f.Created); err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
+
+func (r *Repository) ForumExists(forumSlug string) error {
+	var slug string
+	err := r.db.QueryRow("selectFilterForumSlug", forumSlug).Scan(&slug)
+	if err == pgx.ErrNoRows {
+		return customErr.ErrForumNotFound
+	}
+	return err
+}
+
+func (r *Repository) SetWordFilter(filter *models.WordFilter, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow("upsertWordFilter", filter.Forum, filter.Word, filter.Action).
+		Scan(&filter.Forum, &filter.Word, &filter.Action, &filter.Created)
+	if driverErr, ok := err.(pgx.PgError); ok && driverErr.Code == "23502" {
+		_ = tx.Rollback()
+		return customErr.ErrForumNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) DeleteWordFilter(forumSlug string, word string, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec("deleteWordFilter", forumSlug, word)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback()
+		return customErr.ErrFilterWordNotFound
+	}
+	return tx.Commit()
+}
+
+// GetAuthorCreated время регистрации автора. Для неизвестного автора
+// возвращается нулевое время: его запись отклонит проверка автора.
+func (r *Repository) GetAuthorCreated(nickname string) (time.Time, error) {
+	var created time.Time
+	err := r.db.QueryRow("selectAuthorCreated", nickname).Scan(&created)
+	if err == pgx.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return created, err
+}
+
+// HasRecent проверяет, публиковал ли автор запись kind с тем же текстом
+// после since.
+func (r *Repository) HasRecent(kind string, author string, message string, since time.Time) (bool, error) {
+	name := "selectRecentPost"
+	if kind == "thread" {
+		name = "selectRecentThread"
+	}
+	var exists bool
+	err := r.db.QueryRow(name, author, message, since).Scan(&exists)
+	return exists, err
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectWordFilters", selectWordFilters)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectFilterForumSlug", selectFilterForumSlug)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("upsertWordFilter", upsertWordFilter)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteWordFilter", deleteWordFilter)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectAuthorCreated", selectAuthorCreated)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRecentPost", selectRecentPost)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRecentThread", selectRecentThread)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	filterRepo "DBForum/internal/app/filter/repository"
+	"DBForum/internal/app/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordFilter применяет списки запрещённых слов форумов. Слова сравниваются
+// целиком и без учёта регистра.
+type wordFilter struct {
+	repo filterRepo.Repository
+}
+
+func (f *wordFilter) Check(batch []models.Content) ([]models.Verdict, error) {
+	verdicts := make([]models.Verdict, len(batch))
+	// В пакете обычно записи одного форума: список читается один раз.
+	words := map[string]map[string]string{}
+	for i := range batch {
+		forum := strings.ToLower(batch[i].Forum)
+		actions, ok := words[forum]
+		if !ok {
+			filters, err := f.repo.GetWordFilters(batch[i].Forum)
+			if err != nil {
+				return nil, err
+			}
+			actions = make(map[string]string, len(filters))
+			for _, filter := range filters {
+				actions[strings.ToLower(filter.Word)] = filter.Action
+			}
+			words[forum] = actions
+		}
+		if len(actions) == 0 {
+			continue
+		}
+		var title, message models.Verdict
+		batch[i].Title, title = applyWords(batch[i].Title, actions)
+		batch[i].Message, message = applyWords(batch[i].Message, actions)
+		verdicts[i] = strongest(title, message)
+	}
+	return verdicts, nil
+}
+
+// applyWords маскирует слова с действием mask и возвращает самое строгое из
+// остальных действий.
+func applyWords(text string, actions map[string]string) (string, models.Verdict) {
+	var verdict models.Verdict
+	var masked strings.Builder
+	last := 0
+	for start := 0; start < len(text); {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		if isSeparator(r) {
+			start += size
+			continue
+		}
+		end := start + strings.IndexFunc(text[start:], isSeparator)
+		if end < start {
+			end = len(text)
+		}
+		word := text[start:end]
+		switch action := actions[strings.ToLower(word)]; action {
+		case ActionMask:
+			masked.WriteString(text[last:start])
+			masked.WriteString(strings.Repeat("*", utf8.RuneCountInString(word)))
+			last = end
+		case ActionReject, ActionHold:
+			verdict = strongest(verdict, models.Verdict{
+				Action: action,
+				Reason: "banned word: " + word,
+			})
+		}
+		start = end
+	}
+	if last == 0 {
+		return text, verdict
+	}
+	masked.WriteString(text[last:])
+	return masked.String(), verdict
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+func strongest(a models.Verdict, b models.Verdict) models.Verdict {
+	if a.Action == ActionReject || b.Action == "" {
+		return a
+	}
+	if b.Action == ActionReject || a.Action == "" {
+		return b
+	}
+	return a
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)`)
+
+// linkFilter ограничивает число ссылок в записях недавно
+// зарегистрированных пользователей.
+type linkFilter struct {
+	repo       filterRepo.Repository
+	newUserAge time.Duration
+	maxLinks   int
+}
+
+func (f *linkFilter) Check(batch []models.Content) ([]models.Verdict, error) {
+	verdicts := make([]models.Verdict, len(batch))
+	registered := map[string]time.Time{}
+	for i, content := range batch {
+		links := len(linkPattern.FindAllStringIndex(content.Title, -1)) +
+			len(linkPattern.FindAllStringIndex(content.Message, -1))
+		if links <= f.maxLinks {
+			continue
+		}
+		author := strings.ToLower(content.Author)
+		created, ok := registered[author]
+		if !ok {
+			var err error
+			if created, err = f.repo.GetAuthorCreated(content.Author); err != nil {
+				return nil, err
+			}
+			registered[author] = created
+		}
+		if time.Since(created) < f.newUserAge {
+			verdicts[i] = models.Verdict{
+				Action: ActionReject,
+				Reason: "too many links for a new account: " + strconv.Itoa(links) +
+					", allowed " + strconv.Itoa(f.maxLinks),
+			}
+		}
+	}
+	return verdicts, nil
+}
+
+// duplicateFilter отклоняет повтор автором своего текста в течение окна,
+// в том числе внутри одного пакета.
+type duplicateFilter struct {
+	repo   filterRepo.Repository
+	window time.Duration
+}
+
+func (f *duplicateFilter) Check(batch []models.Content) ([]models.Verdict, error) {
+	verdicts := make([]models.Verdict, len(batch))
+	since := time.Now().Add(-f.window)
+	seen := map[[2]string]bool{}
+	for i, content := range batch {
+		if content.Message == "" {
+			continue
+		}
+		key := [2]string{strings.ToLower(content.Author), content.Message}
+		duplicate := seen[key]
+		seen[key] = true
+		if !duplicate {
+			var err error
+			duplicate, err = f.repo.HasRecent(content.Kind, content.Author, content.Message, since)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if duplicate {
+			verdicts[i] = models.Verdict{
+				Action: ActionReject,
+				Reason: "duplicate message",
+			}
+		}
+	}
+	return verdicts, nil
+}
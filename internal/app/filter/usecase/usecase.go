@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"DBForum/internal/app/config"
+	customErr "DBForum/internal/app/errors"
+	filterRepo "DBForum/internal/app/filter/repository"
+	"DBForum/internal/app/models"
+	"strings"
+)
+
+// Действия фильтров содержимого.
+const (
+	ActionReject = "reject"
+	ActionMask   = "mask"
+	ActionHold   = "hold"
+)
+
+// Filter проверяет пакет записей перед сохранением и возвращает решение для
+// каждой записи. Фильтр может изменить текст записей пакета, например
+// замаскировать слова; такие изменения видят следующие фильтры и сохраняются.
+type Filter interface {
+	Check(batch []models.Content) ([]models.Verdict, error)
+}
+
+type UseCase struct {
+	repo    filterRepo.Repository
+	filters []Filter
+}
+
+// NewUseCase собирает цепочку из встроенных фильтров и дополнительных
+// фильтров hooks, которые выполняются после встроенных в заданном порядке.
+func NewUseCase(repo filterRepo.Repository, policy config.FilterPolicy, hooks ...Filter) *UseCase {
+	filters := []Filter{
+		&wordFilter{repo: repo},
+	}
+	if policy.NewUserAge > 0 {
+		filters = append(filters, &linkFilter{repo: repo, newUserAge: policy.NewUserAge, maxLinks: policy.MaxLinks})
+	}
+	if policy.DuplicateWindow > 0 {
+		filters = append(filters, &duplicateFilter{repo: repo, window: policy.DuplicateWindow})
+	}
+	return &UseCase{
+		repo:    repo,
+		filters: append(filters, hooks...),
+	}
+}
+
+// Check пропускает пакет через цепочку фильтров. Отклонённая запись
+// останавливает проверку и возвращается как *errors.ContentError с её номером
+// в пакете. Для остальных записей возвращаются итоговые решения: hold или
+// пустое действие.
+func (u *UseCase) Check(batch []models.Content) ([]models.Verdict, error) {
+	result := make([]models.Verdict, len(batch))
+	for _, filter := range u.filters {
+		verdicts, err := filter.Check(batch)
+		if err != nil {
+			return nil, err
+		}
+		for i, verdict := range verdicts {
+			switch verdict.Action {
+			case ActionReject:
+				return nil, &customErr.ContentError{Index: i, Reason: verdict.Reason}
+			case ActionHold:
+				if result[i].Action == "" {
+					result[i] = verdict
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// CheckPosts проверяет сообщения пакета в форуме forumSlug, маскирует их
+// текст и отмечает задержанные.
+func (u *UseCase) CheckPosts(forumSlug string, posts []models.Post) error {
+	batch := make([]models.Content, len(posts))
+	for i, post := range posts {
+		batch[i] = models.Content{
+			Kind:    "post",
+			Forum:   forumSlug,
+			Author:  post.Author,
+			Message: post.Message,
+		}
+	}
+	verdicts, err := u.Check(batch)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		posts[i].Message = batch[i].Message
+		posts[i].Held = verdicts[i].Action == ActionHold
+		posts[i].HoldReason = verdicts[i].Reason
+	}
+	return nil
+}
+
+func (u *UseCase) CheckThread(thread *models.Thread) error {
+	batch := []models.Content{{
+		Kind:    "thread",
+		Forum:   thread.Forum,
+		Author:  thread.Author,
+		Title:   thread.Title,
+		Message: thread.Message,
+	}}
+	verdicts, err := u.Check(batch)
+	if err != nil {
+		return err
+	}
+	thread.Title = batch[0].Title
+	thread.Message = batch[0].Message
+	thread.Held = verdicts[0].Action == ActionHold
+	thread.HoldReason = verdicts[0].Reason
+	return nil
+}
+
+func (u *UseCase) GetWordFilters(forumSlug string) ([]models.WordFilter, error) {
+	if err := u.repo.ForumExists(forumSlug); err != nil {
+		return nil, err
+	}
+	filters, err := u.repo.GetWordFilters(forumSlug)
+	if err != nil {
+		return nil, err
+	}
+	if filters == nil {
+		return []models.WordFilter{}, nil
+	}
+	return filters, nil
+}
+
+// SetWordFilter добавляет слово в список форума или меняет действие для него.
+func (u *UseCase) SetWordFilter(filter models.WordFilter, actor models.Actor) (models.WordFilter, error) {
+	switch filter.Action {
+	case ActionReject, ActionMask, ActionHold:
+	default:
+		return models.WordFilter{}, customErr.ErrUnknownFilterAction
+	}
+	filter.Word = strings.TrimSpace(filter.Word)
+	if filter.Word == "" || strings.IndexFunc(filter.Word, isSeparator) >= 0 {
+		return models.WordFilter{}, customErr.ErrInvalidFilterWord
+	}
+	if err := u.repo.SetWordFilter(&filter, actor); err != nil {
+		return models.WordFilter{}, err
+	}
+	return filter, nil
+}
+
+func (u *UseCase) DeleteWordFilter(forumSlug string, word string, actor models.Actor) error {
+	return u.repo.DeleteWordFilter(forumSlug, strings.TrimSpace(word), actor)
+}
@@ -4,6 +4,7 @@ import (
 	customErr "DBForum/internal/app/errors"
 	forumUseCase "DBForum/internal/app/forum/usecase"
 	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
 	"DBForum/internal/app/models"
 	"errors"
 	"github.com/mailru/easyjson"
@@ -14,11 +15,13 @@ import (
 
 type Handlers struct {
 	useCase forumUseCase.UseCase
+	pager   httputils.Pager
 }
 
-func NewHandler(useCase forumUseCase.UseCase) *Handlers {
+func NewHandler(useCase forumUseCase.UseCase, pager httputils.Pager) *Handlers {
 	return &Handlers{
 		useCase: useCase,
+		pager:   pager,
 	}
 }
 
@@ -31,9 +34,15 @@ func (h *Handlers) Create(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	var ok bool
+	if forum.User, ok = middleware.BindAuthor(ctx, forum.User); !ok {
+		middleware.RespondAuthorMismatch(ctx, forum.User)
+		return
+	}
+
 	var err error
 	nickname := forum.User
-	forum, err = h.useCase.CreateForum(forum)
+	forum, err = h.useCase.CreateForum(forum, middleware.Actor(ctx))
 	if errors.Is(err, customErr.ErrUserNotFound) {
 		resp := map[string]string{
 			"message": "Can't find user with nickname: " + nickname,
@@ -79,12 +88,18 @@ func (h *Handlers) CreateThread(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	var ok bool
+	if thread.Author, ok = middleware.BindAuthor(ctx, thread.Author); !ok {
+		middleware.RespondAuthorMismatch(ctx, thread.Author)
+		return
+	}
+
 	forumSlug := ctx.UserValue("slug").(string)
 	nickname := thread.Author
 	thread.Forum = forumSlug
 
 	var err error
-	thread, err = h.useCase.CreateThread(thread)
+	thread, err = h.useCase.CreateThread(thread, middleware.Actor(ctx))
 	if errors.Is(err, customErr.ErrUserNotFound) {
 		resp := map[string]string{
 			"message": "Can't find thread author by nickname: " + nickname,
@@ -103,6 +118,12 @@ func (h *Handlers) CreateThread(ctx *fasthttp.RequestCtx) {
 		httputils.Respond(ctx, http.StatusConflict, thread)
 		return
 	}
+	if httputils.RespondBanned(ctx, err) {
+		return
+	}
+	if httputils.RespondRejected(ctx, err) {
+		return
+	}
 	if err != nil {
 		httputils.Respond(ctx, http.StatusInternalServerError, nil)
 		log.Println(err)
@@ -113,18 +134,33 @@ func (h *Handlers) CreateThread(ctx *fasthttp.RequestCtx) {
 
 func (h *Handlers) GetUsers(ctx *fasthttp.RequestCtx) {
 	forumSlug := ctx.UserValue("slug").(string)
-	// максимальное количество возвращаемых записей
-	limit := ctx.QueryArgs().GetUintOrZero("limit")
-	// Идентификатор пользователя, с которого будут выводиться пользоватли
-	//(пользователь с данным идентификатором в результат не попадает).
-
-	since := string(ctx.QueryArgs().Peek("since"))
-	// Флаг сортировки по убыванию.
-	desc := ctx.QueryArgs().GetBool("desc")
+	// since - никнейм пользователя, с которого будут выводиться пользователи
+	// (пользователь с данным никнеймом в результат не попадает).
+	//
+	// Вид сортировки:
+	// nickname - по никнейму;
+	// reputation - по репутации пользователя;
+	// posts - по числу постов в форуме;
+	// first_active, last_active - по дате первого и последнего сообщения в форуме;
+	// joined - по дате вступления в форум, то есть первого сообщения в нём.
+	// При равенстве участники упорядочены по никнейму.
+	//
+	// Default value : nickname
+	//
+	// Фильтр: moderators - модераторы форума и глобальные, banned - забаненные
+	// в форуме или глобально.
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{
+		Since:   httputils.SinceNickname,
+		Sorts:   []string{"nickname", "reputation", "posts", "first_active", "last_active", "joined"},
+		Filters: []string{"moderators", "banned"},
+	})
+	if !ok {
+		return
+	}
 
 	var users models.UserList
 	var err error
-	users, err = h.useCase.GetForumUsers(forumSlug, limit, since, desc)
+	users, err = h.useCase.GetForumUsers(forumSlug, page.Limit, page.Since, page.Desc, page.Sort, page.Filter, middleware.Caller(ctx))
 	if errors.Is(err, customErr.ErrForumNotFound) {
 		resp := map[string]string{
 			"message": "Can't find forum by slug: " + forumSlug,
@@ -145,16 +181,15 @@ func (h *Handlers) GetThreads(ctx *fasthttp.RequestCtx) {
 	forumSlug := ctx.UserValue("slug").(string)
 	var threads models.ThreadList
 
-	// максимальное количество возвращаемых записей
-	limit, _ := ctx.QueryArgs().GetUint("limit")
-	// Дата создания ветви обсуждения, с которой будут выводиться записи
-	// (ветвь обсуждения с указанной датой попадает в результат выборки).
-	since := string(ctx.QueryArgs().Peek("since"))
-	// Флаг сортировки по убыванию.
-	desc := ctx.QueryArgs().GetBool("desc")
+	// since - дата создания ветви обсуждения, с которой будут выводиться
+	// записи (ветвь обсуждения с указанной датой попадает в результат выборки).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceTime})
+	if !ok {
+		return
+	}
 
 	var err error
-	threads, err = h.useCase.GetForumThreads(forumSlug, limit, since, desc)
+	threads, err = h.useCase.GetForumThreads(forumSlug, page.Limit, page.Since, page.Desc, middleware.Caller(ctx))
 	if errors.Is(err, customErr.ErrForumNotFound) {
 		resp := map[string]string{
 			"message": "Can't find forum by slug: " + forumSlug,
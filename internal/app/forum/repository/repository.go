@@ -1,6 +1,7 @@
 package repository
 
 import (
+	auditRepo "DBForum/internal/app/audit/repository"
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/models"
 	"github.com/jackc/pgx"
@@ -32,8 +33,8 @@ func NewRepo(db *pgx.ConnPool) *Repository {
 	}
 }
 
-func (r *Repository) CreateForum(forum *models.Forum) error {
-	tx, err := r.db.Begin()
+func (r *Repository) CreateForum(forum *models.Forum, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return err
 	}
@@ -1,28 +1,35 @@
 package usecase
 
 import (
+	blockUseCase "DBForum/internal/app/block/usecase"
+	filterUseCase "DBForum/internal/app/filter/usecase"
 	forumRepo "DBForum/internal/app/forum/repository"
 	"DBForum/internal/app/models"
 	threadRepo "DBForum/internal/app/thread/repository"
 	userRepo "DBForum/internal/app/user/repository"
+	userUseCase "DBForum/internal/app/user/usecase"
 )
 
 type UseCase struct {
 	forumRepo  forumRepo.Repository
 	userRepo   userRepo.Repository
 	threadRepo threadRepo.Repository
+	filters    filterUseCase.UseCase
+	blocks     blockUseCase.UseCase
 }
 
-func NewUseCase(forumRepo forumRepo.Repository, userRepo userRepo.Repository, threadRepo threadRepo.Repository) *UseCase {
+func NewUseCase(forumRepo forumRepo.Repository, userRepo userRepo.Repository, threadRepo threadRepo.Repository, filters filterUseCase.UseCase, blocks blockUseCase.UseCase) *UseCase {
 	return &UseCase{
 		forumRepo:  forumRepo,
 		userRepo:   userRepo,
 		threadRepo: threadRepo,
+		filters:    filters,
+		blocks:     blocks,
 	}
 }
 
-func (u *UseCase) CreateForum(forum *models.Forum) (*models.Forum, error) {
-	err := u.forumRepo.CreateForum(forum)
+func (u *UseCase) CreateForum(forum *models.Forum, actor models.Actor) (*models.Forum, error) {
+	err := u.forumRepo.CreateForum(forum, actor)
 	if err != nil {
 		return forum, err
 	}
@@ -37,33 +44,43 @@ func (u *UseCase) GetInfoBySlug(slug string) (*models.Forum, error) {
 	return forum, nil
 }
 
-func (u *UseCase) CreateThread(thread *models.Thread) (*models.Thread, error) {
-	thread, err := u.threadRepo.CreateThread(thread)
+func (u *UseCase) CreateThread(thread *models.Thread, actor models.Actor) (*models.Thread, error) {
+	if err := u.filters.CheckThread(thread); err != nil {
+		return nil, err
+	}
+	thread, err := u.threadRepo.CreateThread(thread, actor)
 	if err != nil {
 		return thread, err
 	}
 	return thread, nil
 }
 
-func (u *UseCase) GetForumUsers(forumSlug string, limit int, since string, desc bool) ([]models.User, error) {
-	if limit == 0 {
-		limit = 100
-	}
-	users, err := u.userRepo.GetForumUsers(forumSlug, limit, since, desc)
+// GetForumUsers участники форума; скрытые поля профиля видны только их
+// владельцу.
+func (u *UseCase) GetForumUsers(forumSlug string, limit int, since string, desc bool, sort string, filter string, viewer string) ([]models.User, error) {
+	users, err := u.userRepo.GetForumUsers(forumSlug, limit, since, desc, sort, filter)
 	if err != nil {
 		return nil, err
 	}
+	for i := range users {
+		userUseCase.ApplyPrivacy(&users[i], viewer)
+	}
 	if users == nil {
 		return []models.User{}, nil
 	}
 	return users, nil
 }
 
-func (u *UseCase) GetForumThreads(forumSlug string, limit int, since string, desc bool) ([]models.Thread, error) {
+// GetForumThreads ветки форума; ветки пользователей, заблокированных viewer,
+// свёрнуты или убраны.
+func (u *UseCase) GetForumThreads(forumSlug string, limit int, since string, desc bool, viewer string) ([]models.Thread, error) {
 	threads, err := u.threadRepo.GetForumThreads(forumSlug, limit, since, desc)
 	if err != nil {
 		return nil, err
 	}
+	if threads, err = u.blocks.FilterThreads(viewer, threads); err != nil {
+		return nil, err
+	}
 	if threads == nil {
 		return []models.Thread{}, nil
 	}
@@ -1,10 +1,14 @@
 package httputils
 
 import (
+	customErr "DBForum/internal/app/errors"
 	"encoding/json"
+	"errors"
 	"github.com/mailru/easyjson"
 	"github.com/valyala/fasthttp"
 	"log"
+	"net/http"
+	"time"
 )
 
 func Respond(ctx *fasthttp.RequestCtx, code int, data easyjson.Marshaler) {
@@ -31,3 +35,42 @@ func RespondErr(ctx *fasthttp.RequestCtx, code int, data interface{}) {
 		}
 	}
 }
+
+// RespondBanned отвечает 403 с причиной и сроком бана, если err - бан.
+func RespondBanned(ctx *fasthttp.RequestCtx, err error) bool {
+	var banErr *customErr.BanError
+	if !errors.As(err, &banErr) {
+		return false
+	}
+	message := "User " + banErr.Nickname + " is banned"
+	if banErr.Forum != "" {
+		message += " in forum " + banErr.Forum
+	}
+	resp := map[string]string{
+		"code":    "banned",
+		"message": message,
+		"reason":  banErr.Reason,
+	}
+	if banErr.Until != nil {
+		resp["until"] = banErr.Until.Format(time.RFC3339)
+	}
+	RespondErr(ctx, http.StatusForbidden, resp)
+	return true
+}
+
+// RespondRejected отвечает 400 с номером записи пакета и причиной, если err -
+// отказ фильтра содержимого.
+func RespondRejected(ctx *fasthttp.RequestCtx, err error) bool {
+	var contentErr *customErr.ContentError
+	if !errors.As(err, &contentErr) {
+		return false
+	}
+	resp := map[string]interface{}{
+		"code":    "content_rejected",
+		"message": "Content rejected: " + contentErr.Reason,
+		"index":   contentErr.Index,
+		"reason":  contentErr.Reason,
+	}
+	RespondErr(ctx, http.StatusBadRequest, resp)
+	return true
+}
@@ -0,0 +1,135 @@
+package httputils
+
+import (
+	"DBForum/internal/app/config"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Since вид параметра since списка.
+type Since int
+
+const (
+	// SinceNone список без since.
+	SinceNone Since = iota
+	// SinceID идентификатор записи.
+	SinceID
+	// SinceTime дата в формате RFC 3339.
+	SinceTime
+	// SinceNickname никнейм пользователя.
+	SinceNickname
+)
+
+// PageSpec параметры, которые принимает список. Первое значение Sorts -
+// сортировка по умолчанию; пустой Sorts - список без sort. Filters -
+// допустимые значения filter, пустой filter означает список без фильтра;
+// пустой Filters - список без filter.
+type PageSpec struct {
+	Since   Since
+	Sorts   []string
+	Filters []string
+}
+
+// Page проверенные параметры страницы списка. Since - исходное значение,
+// для SinceID оно же в SinceID.
+type Page struct {
+	Limit   int
+	Since   string
+	SinceID uint64
+	Desc    bool
+	Sort    string
+	Filter  string
+}
+
+// Pager разбирает limit, since, desc и sort списков с размером страницы из
+// конфигурации.
+type Pager struct {
+	limits config.Page
+}
+
+func NewPager(limits config.Page) Pager {
+	return Pager{
+		limits: limits,
+	}
+}
+
+// Parse читает параметры страницы по spec. Если какие-то из них неверны,
+// отвечает 400 с сообщением для каждого такого параметра в errors и
+// возвращает false.
+func (p Pager) Parse(ctx *fasthttp.RequestCtx, spec PageSpec) (Page, bool) {
+	args := ctx.QueryArgs()
+	page := Page{
+		Limit: p.limits.Default,
+	}
+	errs := map[string]string{}
+
+	if value := string(args.Peek("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > p.limits.Max {
+			errs["limit"] = "must be an integer from 1 to " + strconv.Itoa(p.limits.Max)
+		}
+		page.Limit = limit
+	}
+
+	page.Since = string(args.Peek("since"))
+	if page.Since != "" {
+		switch spec.Since {
+		case SinceID:
+			id, err := strconv.ParseUint(page.Since, 10, 64)
+			if err != nil {
+				errs["since"] = "must be a record id"
+			}
+			page.SinceID = id
+		case SinceTime:
+			if _, err := time.Parse(time.RFC3339Nano, page.Since); err != nil {
+				errs["since"] = "must be an RFC 3339 date"
+			}
+		}
+	}
+
+	switch value := string(args.Peek("desc")); value {
+	case "", "false", "0":
+	case "true", "1":
+		page.Desc = true
+	default:
+		errs["desc"] = "must be true or false"
+	}
+
+	if len(spec.Sorts) > 0 {
+		page.Sort = string(args.Peek("sort"))
+		if page.Sort == "" {
+			page.Sort = spec.Sorts[0]
+		}
+		known := false
+		for _, sort := range spec.Sorts {
+			known = known || sort == page.Sort
+		}
+		if !known {
+			errs["sort"] = "must be one of " + strings.Join(spec.Sorts, ", ")
+		}
+	}
+
+	if len(spec.Filters) > 0 {
+		page.Filter = string(args.Peek("filter"))
+		known := page.Filter == ""
+		for _, filter := range spec.Filters {
+			known = known || filter == page.Filter
+		}
+		if !known {
+			errs["filter"] = "must be one of " + strings.Join(spec.Filters, ", ")
+		}
+	}
+
+	if len(errs) > 0 {
+		resp := map[string]interface{}{
+			"message": "Invalid query parameters",
+			"errors":  errs,
+		}
+		RespondErr(ctx, http.StatusBadRequest, resp)
+		return page, false
+	}
+	return page, true
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	mentionUseCase "DBForum/internal/app/mention/usecase"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase mentionUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase mentionUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+func (h *Handlers) GetMentions(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	// since - идентификатор упоминания, после которого будут выводиться записи
+	// (упоминание с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+
+	var mentions models.MentionList
+	mentions, err := h.useCase.GetMentions(nickname, page.Limit, page.SinceID, page.Desc)
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, mentions)
+}
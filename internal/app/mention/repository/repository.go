@@ -0,0 +1,97 @@
+package repository
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+)
+
+const (
+	mentionColumns = "m.id, m.created, p.id, p.author_nickname, p.forum_slug, p.thread_id, p.message, " +
+		"p.parent, p.is_edited, p.created, p.edit_count, p.last_edited"
+
+	selectMentions = "SELECT " + mentionColumns + " FROM dbforum.mentions AS m " +
+		"JOIN dbforum.post AS p ON p.id = m.post_id " +
+		"WHERE m.nickname = $1 AND NOT p.hidden AND ($2::bigint = 0 OR m.id > $2) " +
+		"ORDER BY m.id LIMIT $3"
+
+	selectMentionsDesc = "SELECT " + mentionColumns + " FROM dbforum.mentions AS m " +
+		"JOIN dbforum.post AS p ON p.id = m.post_id " +
+		"WHERE m.nickname = $1 AND NOT p.hidden AND ($2::bigint = 0 OR m.id < $2) " +
+		"ORDER BY m.id DESC LIMIT $3"
+
+	selectMentionedUser = "SELECT nickname FROM dbforum.users WHERE nickname = $1"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GetMentions упоминания пользователя в видимых сообщениях. since -
+// идентификатор упоминания, после которого выводятся записи.
+func (r *Repository) GetMentions(nickname string, limit int, since uint64, desc bool) ([]models.Mention, error) {
+	var user string
+	err := r.db.QueryRow("selectMentionedUser", nickname).Scan(&user)
+	if err == pgx.ErrNoRows {
+		return nil, customErr.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	name := "selectMentions"
+	if desc {
+		name = "selectMentionsDesc"
+	}
+	rows, err := r.db.Query(name, user, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var mentions []models.Mention
+	for rows.Next() {
+		m := models.Mention{Post: &models.Post{}}
+		err := rows.Scan(
+			&m.ID,
+			&m.Created,
+			&m.Post.ID,
+			&m.Post.Author,
+			&m.Post.Forum,
+			&m.Post.Thread,
+			&m.Post.Message,
+			&m.Post.Parent,
+			&m.Post.IsEdited,
+			&m.Post.Created,
+			&m.Post.EditCount,
+			&m.Post.LastEdited)
+		if err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, rows.Err()
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectMentions", selectMentions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectMentionsDesc", selectMentionsDesc)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectMentionedUser", selectMentionedUser)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	mentionRepo "DBForum/internal/app/mention/repository"
+	"DBForum/internal/app/models"
+)
+
+type UseCase struct {
+	repo mentionRepo.Repository
+}
+
+func NewUseCase(repo mentionRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) GetMentions(nickname string, limit int, since uint64, desc bool) ([]models.Mention, error) {
+	mentions, err := u.repo.GetMentions(nickname, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	if mentions == nil {
+		return []models.Mention{}, nil
+	}
+	return mentions, nil
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	authUseCase "DBForum/internal/app/auth/usecase"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/models"
+	"errors"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Ключ, под которым в контексте запроса хранится сессия вызывающего.
+// Отличается от параметра маршрута nickname.
+const sessionKey = "auth_session"
+
+// Ключи проверки авторов анонимного запроса и её закешированных результатов.
+const (
+	anonymousKey        = "auth_anonymous"
+	anonymousCheckedKey = "auth_anonymous_checked"
+)
+
+// authorCheck разрешает анонимному запросу действовать от имени author.
+type authorCheck func(author string) (bool, error)
+
+type Auth struct {
+	useCase  authUseCase.UseCase
+	required bool
+}
+
+func NewAuth(useCase authUseCase.UseCase, required bool) *Auth {
+	return &Auth{
+		useCase:  useCase,
+		required: required,
+	}
+}
+
+// Handle проверяет заголовок Authorization: Bearer <token>. Запрос без токена
+// пропускается, если аутентификация не обязательна.
+func (a *Auth) Handle(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		header := string(ctx.Request.Header.Peek("Authorization"))
+		if header == "" {
+			if a.required {
+				respondUnauthorized(ctx, "Authentication required")
+				return
+			}
+			ctx.SetUserValue(anonymousKey, authorCheck(a.checkAnonymous))
+			next(ctx)
+			return
+		}
+		if !strings.HasPrefix(header, "Bearer ") {
+			respondUnauthorized(ctx, "Unsupported authorization scheme")
+			return
+		}
+		session, err := a.useCase.Authenticate(strings.TrimPrefix(header, "Bearer "))
+		if errors.Is(err, customErr.ErrInvalidToken) {
+			respondUnauthorized(ctx, "Invalid or expired token")
+			return
+		}
+		if err != nil {
+			httputils.Respond(ctx, http.StatusInternalServerError, nil)
+			log.Println(err)
+			return
+		}
+		ctx.SetUserValue(sessionKey, session)
+		next(ctx)
+	}
+}
+
+// checkAnonymous без токена можно действовать только от имени аккаунтов без
+// пароля.
+func (a *Auth) checkAnonymous(author string) (bool, error) {
+	protected, err := a.useCase.IsProtected(author)
+	if err != nil {
+		return false, err
+	}
+	return !protected, nil
+}
+
+// RequireAuth как Handle, но всегда требует токен.
+func (a *Auth) RequireAuth(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return (&Auth{useCase: a.useCase, required: true}).Handle(next)
+}
+
+// Optional как Handle, но никогда не требует токен: вызывающий определяется,
+// только если токен передан.
+func (a *Auth) Optional(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return (&Auth{useCase: a.useCase}).Handle(next)
+}
+
+// CallerSession возвращает сессию аутентифицированного вызывающего.
+func CallerSession(ctx *fasthttp.RequestCtx) (models.Session, bool) {
+	session, ok := ctx.UserValue(sessionKey).(models.Session)
+	return session, ok
+}
+
+// Caller возвращает никнейм аутентифицированного вызывающего или пустую строку.
+func Caller(ctx *fasthttp.RequestCtx) string {
+	session, _ := CallerSession(ctx)
+	return session.Nickname
+}
+
+// BindAuthor привязывает автора из тела запроса к вызывающему: пустой автор
+// заменяется никнеймом вызывающего, несовпадающий автор отклоняется. В
+// анонимном запросе автор проверяется проверками, установленными Auth и
+// Permissions.
+func BindAuthor(ctx *fasthttp.RequestCtx, author string) (string, bool) {
+	caller := Caller(ctx)
+	if caller == "" {
+		return author, anonymousAllowed(ctx, author)
+	}
+	if author == "" {
+		return caller, true
+	}
+	return author, strings.EqualFold(author, caller)
+}
+
+// BindCaller для операций только от своего имени: автор из тела должен быть
+// пустым или совпадать с вызывающим и заменяется им. Без вызывающего
+// отклоняется любой автор.
+func BindCaller(ctx *fasthttp.RequestCtx, author string) (string, bool) {
+	caller := Caller(ctx)
+	if author != "" && !strings.EqualFold(author, caller) {
+		return author, false
+	}
+	return caller, caller != ""
+}
+
+func anonymousAllowed(ctx *fasthttp.RequestCtx, author string) bool {
+	check, ok := ctx.UserValue(anonymousKey).(authorCheck)
+	if !ok || author == "" {
+		return true
+	}
+	// В пакете постов автор повторяется, проверка делается один раз.
+	checked, _ := ctx.UserValue(anonymousCheckedKey).(map[string]bool)
+	if checked == nil {
+		checked = map[string]bool{}
+		ctx.SetUserValue(anonymousCheckedKey, checked)
+	}
+	key := strings.ToLower(author)
+	if allowed, ok := checked[key]; ok {
+		return allowed
+	}
+	allowed, err := check(author)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	checked[key] = allowed
+	return allowed
+}
+
+// RespondAuthorMismatch стандартный ответ на запрос от имени другого пользователя.
+func RespondAuthorMismatch(ctx *fasthttp.RequestCtx, author string) {
+	resp := map[string]string{
+		"message": "Can't act on behalf of another user: " + author,
+	}
+	httputils.RespondErr(ctx, http.StatusForbidden, resp)
+}
+
+func respondUnauthorized(ctx *fasthttp.RequestCtx, message string) {
+	ctx.Response.Header.Set("WWW-Authenticate", "Bearer")
+	resp := map[string]string{
+		"message": message,
+	}
+	httputils.RespondErr(ctx, http.StatusUnauthorized, resp)
+}
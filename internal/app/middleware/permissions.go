@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/models"
+	roleUseCase "DBForum/internal/app/role/usecase"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+const rolesKey = "auth_roles"
+
+// Permissions проверяет роли вызывающего между обработчиком и сценарием.
+// Должен оборачиваться в Auth, который определяет вызывающего.
+type Permissions struct {
+	useCase roleUseCase.UseCase
+}
+
+func NewPermissions(useCase roleUseCase.UseCase) *Permissions {
+	return &Permissions{
+		useCase: useCase,
+	}
+}
+
+// Load загружает роли вызывающего в контекст запроса для Allowed.
+func (p *Permissions) Load(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !p.load(ctx) {
+			return
+		}
+		next(ctx)
+	}
+}
+
+// Require пропускает только аутентифицированных вызывающих с разрешением perm.
+// Форум берётся из параметра маршрута slug, если он есть.
+func (p *Permissions) Require(perm roleUseCase.Permission, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if Caller(ctx) == "" {
+			respondUnauthorized(ctx, "Authentication required")
+			return
+		}
+		if !p.load(ctx) {
+			return
+		}
+		if !Allowed(ctx, perm, routeForum(ctx)) {
+			respondForbidden(ctx)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// Restrict как Require, но пропускает анонимные запросы: обязательность
+// аутентификации определяет Auth. В анонимном запросе разрешение perm
+// проверяется у автора, которого привязывает BindAuthor.
+func (p *Permissions) Restrict(perm roleUseCase.Permission, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if Caller(ctx) == "" {
+			p.restrictAnonymous(ctx, perm)
+			next(ctx)
+			return
+		}
+		if !p.load(ctx) {
+			return
+		}
+		if !Allowed(ctx, perm, routeForum(ctx)) {
+			respondForbidden(ctx)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// Allowed проверяет разрешение по ролям, загруженным Load или Require.
+func Allowed(ctx *fasthttp.RequestCtx, perm roleUseCase.Permission, forumSlug string) bool {
+	if Caller(ctx) == "" {
+		return false
+	}
+	roles, _ := ctx.UserValue(rolesKey).([]models.Role)
+	return roleUseCase.Allowed(roles, perm, forumSlug)
+}
+
+// restrictAnonymous дополняет проверку автора анонимного запроса ролями
+// автора: readonly-пользователь не обойдёт ограничение, не передав токен.
+func (p *Permissions) restrictAnonymous(ctx *fasthttp.RequestCtx, perm roleUseCase.Permission) {
+	check, _ := ctx.UserValue(anonymousKey).(authorCheck)
+	forumSlug := routeForum(ctx)
+	ctx.SetUserValue(anonymousKey, authorCheck(func(author string) (bool, error) {
+		if check != nil {
+			if allowed, err := check(author); !allowed || err != nil {
+				return allowed, err
+			}
+		}
+		roles, err := p.useCase.GetUserRoles(author)
+		if err != nil {
+			return false, err
+		}
+		return roleUseCase.Allowed(roles, perm, forumSlug), nil
+	}))
+}
+
+func (p *Permissions) load(ctx *fasthttp.RequestCtx) bool {
+	caller := Caller(ctx)
+	if caller == "" {
+		return true
+	}
+	if _, ok := ctx.UserValue(rolesKey).([]models.Role); ok {
+		return true
+	}
+	roles, err := p.useCase.GetUserRoles(caller)
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return false
+	}
+	ctx.SetUserValue(rolesKey, roles)
+	return true
+}
+
+func routeForum(ctx *fasthttp.RequestCtx) string {
+	slug, _ := ctx.UserValue("slug").(string)
+	return slug
+}
+
+func respondForbidden(ctx *fasthttp.RequestCtx) {
+	resp := map[string]string{
+		"message": "Not enough permissions",
+	}
+	httputils.RespondErr(ctx, http.StatusForbidden, resp)
+}
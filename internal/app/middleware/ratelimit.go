@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"DBForum/internal/app/config"
+	"DBForum/internal/app/httputils"
+	"github.com/valyala/fasthttp"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Классы маршрутов с отдельными ограничениями частоты.
+const (
+	RatePost    = "post"
+	RateThread  = "thread"
+	RateVote    = "vote"
+	RateProfile = "profile"
+)
+
+// ThreadForum возвращает форум ветки по её id или slug.
+type ThreadForum func(idOrSlug string) (string, error)
+
+// RateLimiter ограничивает частоту запросов записи алгоритмом token bucket.
+// Корзина заводится на класс маршрута и вызывающего (никнейм или IP-адрес
+// анонимного клиента); у форумов со своим ограничением корзины отдельные.
+// Должен оборачиваться в Auth, который определяет вызывающего.
+type RateLimiter struct {
+	limits      config.RateLimit
+	threadForum ThreadForum
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func NewRateLimiter(limits config.RateLimit, threadForum ThreadForum) *RateLimiter {
+	return &RateLimiter{
+		limits:      limits,
+		threadForum: threadForum,
+		buckets:     map[string]*bucket{},
+		lastSweep:   time.Now(),
+	}
+}
+
+// Limit пропускает запрос, если в корзине класса class есть запрос, иначе
+// отвечает 429 с заголовком Retry-After.
+func (l *RateLimiter) Limit(class string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		rate, forum := l.rate(ctx, class)
+		if rate.Count == 0 {
+			next(ctx)
+			return
+		}
+		caller := Caller(ctx)
+		if caller == "" {
+			caller = "ip:" + ctx.RemoteIP().String()
+		} else {
+			caller = "user:" + strings.ToLower(caller)
+		}
+		wait := l.take(class+"|"+forum+"|"+caller, rate, time.Now())
+		if wait > 0 {
+			seconds := int(math.Ceil(wait.Seconds()))
+			ctx.Response.Header.Set("Retry-After", strconv.Itoa(seconds))
+			resp := map[string]string{
+				"code":    "rate_limited",
+				"message": "Too many requests, retry in " + strconv.Itoa(seconds) + "s",
+			}
+			httputils.RespondErr(ctx, http.StatusTooManyRequests, resp)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// rate ограничение класса для форума запроса. Форум возвращается, только
+// если у него своё ограничение.
+func (l *RateLimiter) rate(ctx *fasthttp.RequestCtx, class string) (config.Rate, string) {
+	if len(l.limits.Forums) > 0 {
+		forum := strings.ToLower(routeForum(ctx))
+		if idOrSlug, ok := ctx.UserValue("slug_or_id").(string); ok && forum == "" && l.threadForum != nil {
+			// Несуществующую ветку отклонит обработчик.
+			if slug, err := l.threadForum(idOrSlug); err == nil {
+				forum = strings.ToLower(slug)
+			}
+		}
+		if rate, ok := l.limits.Forums[forum][class]; ok {
+			return rate, forum
+		}
+	}
+	return l.limits.Classes[class], ""
+}
+
+// take забирает запрос из корзины и возвращает время до появления
+// следующего, если корзина пуста.
+func (l *RateLimiter) take(key string, rate config.Rate, now time.Time) time.Duration {
+	perToken := rate.Per / time.Duration(rate.Count)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Count), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(rate.Count), b.tokens+float64(now.Sub(b.updated))/float64(perToken))
+	b.updated = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep раз в минуту удаляет корзины, не использовавшиеся дольше самого
+// длинного периода: за это время любая из них заполнилась бы целиком.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	idle := l.longestPeriod()
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (l *RateLimiter) longestPeriod() time.Duration {
+	longest := time.Duration(0)
+	for _, rate := range l.limits.Classes {
+		if rate.Per > longest {
+			longest = rate.Per
+		}
+	}
+	for _, classes := range l.limits.Forums {
+		for _, rate := range classes {
+			if rate.Per > longest {
+				longest = rate.Per
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"DBForum/internal/app/models"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	requestIDKey    = "request_id"
+	requestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+// RequestID присваивает запросу идентификатор для журнала аудита: берёт
+// X-Request-ID клиента или генерирует новый и возвращает его в ответе.
+func RequestID(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		id := string(ctx.Request.Header.Peek(requestIDHeader))
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
+		}
+		ctx.SetUserValue(requestIDKey, id)
+		ctx.Response.Header.Set(requestIDHeader, id)
+		next(ctx)
+	}
+}
+
+// Actor возвращает инициатора изменяющей операции для журнала аудита.
+func Actor(ctx *fasthttp.RequestCtx) models.Actor {
+	id, _ := ctx.UserValue(requestIDKey).(string)
+	roles, _ := ctx.UserValue(rolesKey).([]models.Role)
+	return models.Actor{
+		Nickname:  Caller(ctx),
+		RequestID: id,
+		Roles:     roles,
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//easyjson:json
+type AuditLog []AuditEntry
+
+//easyjson:json
+type AuditEntry struct {
+	ID         uint64          `json:"id,omitempty" db:"id"`
+	Actor      string          `json:"actor" db:"actor"`
+	Action     string          `json:"action" db:"action"`
+	TargetType string          `json:"targetType" db:"target_type"`
+	TargetID   string          `json:"targetId" db:"target_id"`
+	Forum      string          `json:"forum,omitempty" db:"forum_slug"`
+	Details    string          `json:"details,omitempty" db:"details"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+	RequestID  string          `json:"requestId,omitempty" db:"request_id"`
+	Created    time.Time       `json:"created,omitempty" db:"created"`
+}
+
+// AuditQuery фильтры GET /api/admin/audit; пустые поля не ограничивают выборку.
+type AuditQuery struct {
+	Actor      string
+	TargetType string
+	TargetID   string
+	Forum      string
+	RequestID  string
+	Limit      int
+	Since      uint64
+	Desc       bool
+}
+
+// Actor инициатор изменяющей операции. Пустой Nickname означает
+// неаутентифицированный запрос: инициатором считается автор записи.
+type Actor struct {
+	Nickname  string
+	RequestID string
+	// Роли вызывающего, если их загрузил слой разрешений.
+	Roles []Role
+}
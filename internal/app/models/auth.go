@@ -0,0 +1,28 @@
+package models
+
+//easyjson:json
+type Credentials struct {
+	Nickname string `json:"nickname,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+//easyjson:json
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+//easyjson:json
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// Отозвать все сессии пользователя, а не только текущую.
+	All bool `json:"all,omitempty"`
+}
+
+// Session сессия, к которой привязаны токен обновления и токены доступа.
+type Session struct {
+	ID       uint64
+	Nickname string
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type BanList []Ban
+
+//easyjson:json
+type Ban struct {
+	ID       uint64     `json:"id,omitempty" db:"id"`
+	Nickname string     `json:"nickname,omitempty" db:"nickname"`
+	Forum    string     `json:"forum,omitempty" db:"forum_slug"`
+	Reason   string     `json:"reason,omitempty" db:"reason"`
+	BannedBy string     `json:"bannedBy,omitempty" db:"banned_by"`
+	Created  time.Time  `json:"created,omitempty" db:"created"`
+	Until    *time.Time `json:"until,omitempty" db:"expires"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type BlockList []Block
+
+// Block заблокированный пользователь. Mute только сворачивает его записи,
+// не ограничивая ответы, упоминания и личные сообщения.
+//
+//easyjson:json
+type Block struct {
+	Nickname string    `json:"nickname" db:"blocked"`
+	Mute     bool      `json:"mute,omitempty"`
+	Created  time.Time `json:"created,omitempty" db:"created"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type ConversationList []Conversation
+
+// Conversation личная переписка. Members - текущие участники, Unread -
+// непрочитанные вызывающим сообщения.
+//
+//easyjson:json
+type Conversation struct {
+	ID          uint64          `json:"id,omitempty" db:"id"`
+	Title       string          `json:"title,omitempty" db:"title"`
+	Author      string          `json:"author,omitempty" db:"created_by"`
+	Members     []string        `json:"members"`
+	Created     time.Time       `json:"created,omitempty" db:"created"`
+	LastMessage *PrivateMessage `json:"lastMessage,omitempty"`
+	Unread      int64           `json:"unread"`
+}
+
+//easyjson:json
+type PrivateMessageList []PrivateMessage
+
+//easyjson:json
+type PrivateMessage struct {
+	ID           uint64    `json:"id,omitempty" db:"id"`
+	Conversation uint64    `json:"conversation,omitempty" db:"conversation_id"`
+	Author       string    `json:"author,omitempty" db:"author_nickname"`
+	Message      string    `json:"message,omitempty" db:"message"`
+	Created      time.Time `json:"created,omitempty" db:"created"`
+}
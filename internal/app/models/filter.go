@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type WordFilterList []WordFilter
+
+// WordFilter запрещённое в форуме слово и действие при его появлении:
+// reject, mask или hold.
+//
+//easyjson:json
+type WordFilter struct {
+	Forum   string    `json:"forum,omitempty" db:"forum_slug"`
+	Word    string    `json:"word" db:"word"`
+	Action  string    `json:"action" db:"action"`
+	Created time.Time `json:"created,omitempty" db:"created"`
+}
+
+// Content текст сообщения или ветки, проверяемый фильтрами перед сохранением.
+type Content struct {
+	// post или thread.
+	Kind    string
+	Forum   string
+	Author  string
+	Title   string
+	Message string
+}
+
+// Verdict решение фильтра по записи. Пустое действие пропускает запись.
+type Verdict struct {
+	Action string
+	Reason string
+}
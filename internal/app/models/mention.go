@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type MentionList []Mention
+
+// Mention упоминание пользователя в сообщении.
+//
+//easyjson:json
+type Mention struct {
+	ID      uint64    `json:"id" db:"id"`
+	Post    *Post     `json:"post"`
+	Created time.Time `json:"created" db:"created"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type NotificationList []Notification
+
+// Notification уведомление о действии actor: reply, thread_reply, mention
+// или vote.
+//
+//easyjson:json
+type Notification struct {
+	ID      uint64     `json:"id" db:"id"`
+	Type    string     `json:"type" db:"type"`
+	Actor   string     `json:"actor" db:"actor"`
+	Post    uint64     `json:"post,omitempty" db:"post_id"`
+	Thread  uint64     `json:"thread" db:"thread_id"`
+	Forum   string     `json:"forum" db:"forum_slug"`
+	Created time.Time  `json:"created" db:"created"`
+	Read    *time.Time `json:"read,omitempty" db:"read"`
+}
+
+// NotificationQuery параметры выборки уведомлений.
+type NotificationQuery struct {
+	Unread bool
+	Limit  int
+	Since  uint64
+	Desc   bool
+}
+
+//easyjson:json
+type NotificationRead struct {
+	IDs []uint64 `json:"ids"`
+}
+
+// NotificationCount число непрочитанных уведомлений, всего и по типам.
+//
+//easyjson:json
+type NotificationCount struct {
+	Unread int64            `json:"unread"`
+	ByType map[string]int64 `json:"byType"`
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"github.com/go-openapi/strfmt"
 	"github.com/lib/pq"
+	"time"
 )
 
 //easyjson:json
@@ -19,6 +20,14 @@ type Post struct {
 	Thread   uint64          `json:"thread,omitempty" db:"thread_id"`
 	Tree     pq.Int64Array   `json:"-" db:"tree"`
 	Created  strfmt.DateTime `json:"created,omitempty" db:"created"`
+	// Количество правок и время последней из них.
+	EditCount  int        `json:"editCount" db:"edit_count"`
+	LastEdited *time.Time `json:"lastEdited,omitempty" db:"last_edited"`
+	// Запись задержана фильтром содержимого до решения модератора.
+	Held       bool   `json:"held,omitempty" db:"hidden"`
+	HoldReason string `json:"-"`
+	// Автор заблокирован вызывающим: текст записи не выводится.
+	Collapsed bool `json:"collapsed,omitempty"`
 }
 
 //easyjson:json
@@ -28,3 +37,20 @@ type PostInfo struct {
 	Thread *Thread `json:"thread,omitempty"`
 	Forum  *Forum  `json:"forum,omitempty"`
 }
+
+// EditInfo сведения о сообщении или ветке для проверки права на правку.
+type EditInfo struct {
+	Author  string
+	Forum   string
+	Created time.Time
+	// У автора есть пароль: анонимно править его записи нельзя.
+	Protected bool
+}
+
+// Visibility сведения о сообщении или ветке для проверки права их читать.
+type Visibility struct {
+	Author string
+	Forum  string
+	// Запись скрыта модератором или задержана фильтром содержимого.
+	Hidden bool
+}
@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -120,7 +121,7 @@ func easyjson5a72dc82DecodeDBForumInternalAppModels1(in *jlexer.Lexer, out *Post
 				if out.Author == nil {
 					out.Author = new(User)
 				}
-				easyjson5a72dc82DecodeDBForumInternalAppModels2(in, out.Author)
+				(*out.Author).UnmarshalEasyJSON(in)
 			}
 		case "thread":
 			if in.IsNull() {
@@ -130,7 +131,7 @@ func easyjson5a72dc82DecodeDBForumInternalAppModels1(in *jlexer.Lexer, out *Post
 				if out.Thread == nil {
 					out.Thread = new(Thread)
 				}
-				easyjson5a72dc82DecodeDBForumInternalAppModels3(in, out.Thread)
+				(*out.Thread).UnmarshalEasyJSON(in)
 			}
 		case "forum":
 			if in.IsNull() {
@@ -170,7 +171,7 @@ func easyjson5a72dc82EncodeDBForumInternalAppModels1(out *jwriter.Writer, in Pos
 		} else {
 			out.RawString(prefix)
 		}
-		easyjson5a72dc82EncodeDBForumInternalAppModels2(out, *in.Author)
+		(*in.Author).MarshalEasyJSON(out)
 	}
 	if in.Thread != nil {
 		const prefix string = ",\"thread\":"
@@ -180,7 +181,7 @@ func easyjson5a72dc82EncodeDBForumInternalAppModels1(out *jwriter.Writer, in Pos
 		} else {
 			out.RawString(prefix)
 		}
-		easyjson5a72dc82EncodeDBForumInternalAppModels3(out, *in.Thread)
+		(*in.Thread).MarshalEasyJSON(out)
 	}
 	if in.Forum != nil {
 		const prefix string = ",\"forum\":"
@@ -218,205 +219,7 @@ func (v *PostInfo) UnmarshalJSON(data []byte) error {
 func (v *PostInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson5a72dc82DecodeDBForumInternalAppModels1(l, v)
 }
-func easyjson5a72dc82DecodeDBForumInternalAppModels3(in *jlexer.Lexer, out *Thread) {
-	isTopLevel := in.IsStart()
-	if in.IsNull() {
-		if isTopLevel {
-			in.Consumed()
-		}
-		in.Skip()
-		return
-	}
-	in.Delim('{')
-	for !in.IsDelim('}') {
-		key := in.UnsafeFieldName(false)
-		in.WantColon()
-		if in.IsNull() {
-			in.Skip()
-			in.WantComma()
-			continue
-		}
-		switch key {
-		case "id":
-			out.ID = uint64(in.Uint64())
-		case "title":
-			out.Title = string(in.String())
-		case "author":
-			out.Author = string(in.String())
-		case "forum":
-			out.Forum = string(in.String())
-		case "message":
-			out.Message = string(in.String())
-		case "votes":
-			out.Votes = int(in.Int())
-		case "slug":
-			out.Slug = string(in.String())
-		case "created":
-			if data := in.Raw(); in.Ok() {
-				in.AddError((out.Created).UnmarshalJSON(data))
-			}
-		default:
-			in.SkipRecursive()
-		}
-		in.WantComma()
-	}
-	in.Delim('}')
-	if isTopLevel {
-		in.Consumed()
-	}
-}
-func easyjson5a72dc82EncodeDBForumInternalAppModels3(out *jwriter.Writer, in Thread) {
-	out.RawByte('{')
-	first := true
-	_ = first
-	if in.ID != 0 {
-		const prefix string = ",\"id\":"
-		first = false
-		out.RawString(prefix[1:])
-		out.Uint64(uint64(in.ID))
-	}
-	if in.Title != "" {
-		const prefix string = ",\"title\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.Title))
-	}
-	if in.Author != "" {
-		const prefix string = ",\"author\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.Author))
-	}
-	if in.Forum != "" {
-		const prefix string = ",\"forum\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.Forum))
-	}
-	if in.Message != "" {
-		const prefix string = ",\"message\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.Message))
-	}
-	{
-		const prefix string = ",\"votes\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.Int(int(in.Votes))
-	}
-	if in.Slug != "" {
-		const prefix string = ",\"slug\":"
-		out.RawString(prefix)
-		out.String(string(in.Slug))
-	}
-	if true {
-		const prefix string = ",\"created\":"
-		out.RawString(prefix)
-		out.Raw((in.Created).MarshalJSON())
-	}
-	out.RawByte('}')
-}
-func easyjson5a72dc82DecodeDBForumInternalAppModels2(in *jlexer.Lexer, out *User) {
-	isTopLevel := in.IsStart()
-	if in.IsNull() {
-		if isTopLevel {
-			in.Consumed()
-		}
-		in.Skip()
-		return
-	}
-	in.Delim('{')
-	for !in.IsDelim('}') {
-		key := in.UnsafeFieldName(false)
-		in.WantColon()
-		if in.IsNull() {
-			in.Skip()
-			in.WantComma()
-			continue
-		}
-		switch key {
-		case "nickname":
-			out.Nickname = string(in.String())
-		case "fullname":
-			out.Fullname = string(in.String())
-		case "about":
-			out.About = string(in.String())
-		case "email":
-			out.Email = string(in.String())
-		default:
-			in.SkipRecursive()
-		}
-		in.WantComma()
-	}
-	in.Delim('}')
-	if isTopLevel {
-		in.Consumed()
-	}
-}
-func easyjson5a72dc82EncodeDBForumInternalAppModels2(out *jwriter.Writer, in User) {
-	out.RawByte('{')
-	first := true
-	_ = first
-	if in.Nickname != "" {
-		const prefix string = ",\"nickname\":"
-		first = false
-		out.RawString(prefix[1:])
-		out.String(string(in.Nickname))
-	}
-	if in.Fullname != "" {
-		const prefix string = ",\"fullname\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.Fullname))
-	}
-	if in.About != "" {
-		const prefix string = ",\"about\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.About))
-	}
-	if in.Email != "" {
-		const prefix string = ",\"email\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.String(string(in.Email))
-	}
-	out.RawByte('}')
-}
-func easyjson5a72dc82DecodeDBForumInternalAppModels4(in *jlexer.Lexer, out *Post) {
+func easyjson5a72dc82DecodeDBForumInternalAppModels2(in *jlexer.Lexer, out *Post) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -453,6 +256,24 @@ func easyjson5a72dc82DecodeDBForumInternalAppModels4(in *jlexer.Lexer, out *Post
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.Created).UnmarshalJSON(data))
 			}
+		case "editCount":
+			out.EditCount = int(in.Int())
+		case "lastEdited":
+			if in.IsNull() {
+				in.Skip()
+				out.LastEdited = nil
+			} else {
+				if out.LastEdited == nil {
+					out.LastEdited = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastEdited).UnmarshalJSON(data))
+				}
+			}
+		case "held":
+			out.Held = bool(in.Bool())
+		case "collapsed":
+			out.Collapsed = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -463,7 +284,7 @@ func easyjson5a72dc82DecodeDBForumInternalAppModels4(in *jlexer.Lexer, out *Post
 		in.Consumed()
 	}
 }
-func easyjson5a72dc82EncodeDBForumInternalAppModels4(out *jwriter.Writer, in Post) {
+func easyjson5a72dc82EncodeDBForumInternalAppModels2(out *jwriter.Writer, in Post) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -513,29 +334,49 @@ func easyjson5a72dc82EncodeDBForumInternalAppModels4(out *jwriter.Writer, in Pos
 		out.RawString(prefix)
 		out.Raw((in.Created).MarshalJSON())
 	}
+	{
+		const prefix string = ",\"editCount\":"
+		out.RawString(prefix)
+		out.Int(int(in.EditCount))
+	}
+	if in.LastEdited != nil {
+		const prefix string = ",\"lastEdited\":"
+		out.RawString(prefix)
+		out.Raw((*in.LastEdited).MarshalJSON())
+	}
+	if in.Held {
+		const prefix string = ",\"held\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Held))
+	}
+	if in.Collapsed {
+		const prefix string = ",\"collapsed\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Collapsed))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Post) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson5a72dc82EncodeDBForumInternalAppModels4(&w, v)
+	easyjson5a72dc82EncodeDBForumInternalAppModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson5a72dc82EncodeDBForumInternalAppModels4(w, v)
+	easyjson5a72dc82EncodeDBForumInternalAppModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Post) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson5a72dc82DecodeDBForumInternalAppModels4(&r, v)
+	easyjson5a72dc82DecodeDBForumInternalAppModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson5a72dc82DecodeDBForumInternalAppModels4(l, v)
+	easyjson5a72dc82DecodeDBForumInternalAppModels2(l, v)
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type Report struct {
+	ID       uint64    `json:"id,omitempty" db:"id"`
+	Type     string    `json:"type,omitempty" db:"target_type"`
+	Target   uint64    `json:"target,omitempty" db:"target_id"`
+	Forum    string    `json:"forum,omitempty" db:"forum_slug"`
+	Reporter string    `json:"reporter,omitempty" db:"reporter"`
+	Reason   string    `json:"reason,omitempty" db:"reason"`
+	Created  time.Time `json:"created,omitempty" db:"created"`
+}
+
+//easyjson:json
+type ModerationQueue []ModerationItem
+
+// ModerationItem сообщение или ветка с нерассмотренными жалобами.
+//
+//easyjson:json
+type ModerationItem struct {
+	Type          string    `json:"type"`
+	Target        uint64    `json:"target"`
+	Forum         string    `json:"forum"`
+	Author        string    `json:"author"`
+	Reports       int       `json:"reports"`
+	Reasons       []string  `json:"reasons"`
+	FirstReported time.Time `json:"firstReported"`
+	LastReported  time.Time `json:"lastReported"`
+}
+
+// ModerationAction решение модератора по всем жалобам на объект.
+//
+//easyjson:json
+type ModerationAction struct {
+	Type   string     `json:"type"`
+	Target uint64     `json:"target"`
+	Action string     `json:"action"`
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+//easyjson:json
+type ModerationResult struct {
+	Action   string `json:"action"`
+	Resolved int64  `json:"resolved"`
+	Ban      *Ban   `json:"ban,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type RevisionList []Revision
+
+// Revision версия сообщения или ветки. Title заполняется только у веток.
+//
+//easyjson:json
+type Revision struct {
+	Revision int       `json:"revision"`
+	Title    string    `json:"title,omitempty"`
+	Message  string    `json:"message"`
+	Editor   string    `json:"editor"`
+	Created  time.Time `json:"created"`
+	Current  bool      `json:"current,omitempty"`
+}
+
+//easyjson:json
+type RevisionDiff struct {
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Title   []DiffLine `json:"title,omitempty"`
+	Message []DiffLine `json:"message"`
+}
+
+// DiffLine строка построчного сравнения: Op - equal, delete или insert.
+//
+//easyjson:json
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
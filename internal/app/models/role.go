@@ -0,0 +1,11 @@
+package models
+
+//easyjson:json
+type RoleList []Role
+
+//easyjson:json
+type Role struct {
+	Nickname string `json:"nickname,omitempty" db:"nickname"`
+	Role     string `json:"role,omitempty" db:"role"`
+	Forum    string `json:"forum,omitempty" db:"forum_slug"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type SearchResult struct {
+	Type    string    `json:"type"`
+	ID      uint64    `json:"id"`
+	Thread  uint64    `json:"thread"`
+	Forum   string    `json:"forum"`
+	Author  string    `json:"author"`
+	Title   string    `json:"title,omitempty"`
+	Snippet string    `json:"snippet"`
+	Rank    float32   `json:"rank"`
+	Created time.Time `json:"created"`
+}
+
+//easyjson:json
+type SearchPage struct {
+	Results []SearchResult `json:"results"`
+	Next    string         `json:"next,omitempty"`
+}
+
+// SearchQuery параметры запроса GET /api/search.
+type SearchQuery struct {
+	Query  string
+	Type   string
+	Forum  string
+	Author string
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	// Ключ последнего результата предыдущей страницы.
+	After *SearchResult
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+//easyjson:json
+type SubscriptionList []Subscription
+
+// Subscription подписка пользователя на ветку (type = thread) или форум
+// (type = forum).
+//
+//easyjson:json
+type Subscription struct {
+	Nickname string    `json:"nickname,omitempty" db:"nickname"`
+	Type     string    `json:"type,omitempty"`
+	Thread   uint64    `json:"thread,omitempty" db:"thread_id"`
+	Forum    string    `json:"forum,omitempty" db:"forum_slug"`
+	Created  time.Time `json:"created,omitempty" db:"created"`
+}
+
+// WatchedFeed новые сообщения подписанных веток и новые ветки подписанных
+// форумов с прошлого просмотра ленты.
+//
+//easyjson:json
+type WatchedFeed struct {
+	Since   time.Time `json:"since"`
+	Posts   []Post    `json:"posts"`
+	Threads []Thread  `json:"threads"`
+}
@@ -17,6 +17,14 @@ type Thread struct {
 	Votes   int       `json:"votes" db:"votes"`
 	Slug    string    `json:"slug,omitempty" db:"slug"`
 	Created time.Time `json:"created,omitempty" db:"created"`
+	// Количество правок и время последней из них.
+	EditCount  int        `json:"editCount" db:"edit_count"`
+	LastEdited *time.Time `json:"lastEdited,omitempty" db:"last_edited"`
+	// Запись задержана фильтром содержимого до решения модератора.
+	Held       bool   `json:"held,omitempty" db:"hidden"`
+	HoldReason string `json:"-"`
+	// Автор заблокирован вызывающим: текст записи не выводится.
+	Collapsed bool `json:"collapsed,omitempty"`
 }
 
 //easyjson:json
@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -199,6 +200,24 @@ func easyjson2d00218DecodeDBForumInternalAppModels2(in *jlexer.Lexer, out *Threa
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.Created).UnmarshalJSON(data))
 			}
+		case "editCount":
+			out.EditCount = int(in.Int())
+		case "lastEdited":
+			if in.IsNull() {
+				in.Skip()
+				out.LastEdited = nil
+			} else {
+				if out.LastEdited == nil {
+					out.LastEdited = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastEdited).UnmarshalJSON(data))
+				}
+			}
+		case "held":
+			out.Held = bool(in.Bool())
+		case "collapsed":
+			out.Collapsed = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -279,6 +298,26 @@ func easyjson2d00218EncodeDBForumInternalAppModels2(out *jwriter.Writer, in Thre
 		out.RawString(prefix)
 		out.Raw((in.Created).MarshalJSON())
 	}
+	{
+		const prefix string = ",\"editCount\":"
+		out.RawString(prefix)
+		out.Int(int(in.EditCount))
+	}
+	if in.LastEdited != nil {
+		const prefix string = ",\"lastEdited\":"
+		out.RawString(prefix)
+		out.Raw((*in.LastEdited).MarshalJSON())
+	}
+	if in.Held {
+		const prefix string = ",\"held\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Held))
+	}
+	if in.Collapsed {
+		const prefix string = ",\"collapsed\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Collapsed))
+	}
 	out.RawByte('}')
 }
 
@@ -1,12 +1,119 @@
 package models
 
+import (
+	"time"
+)
+
 //easyjson:json
 type UserList []User
 
 //easyjson:json
 type User struct {
-	Nickname string `json:"nickname,omitempty" db:"nickname"`
-	Fullname string `json:"fullname,omitempty" db:"fullname"`
-	About    string `json:"about,omitempty" db:"about"`
-	Email    string `json:"email,omitempty" db:"email"`
+	Nickname   string `json:"nickname,omitempty" db:"nickname"`
+	Fullname   string `json:"fullname,omitempty" db:"fullname"`
+	About      string `json:"about,omitempty" db:"about"`
+	Email      string `json:"email,omitempty" db:"email"`
+	Reputation int64  `json:"reputation,omitempty" db:"reputation"`
+
+	// Адрес аватара, отдаваемого сервером из хранилища аватаров.
+	Avatar    string     `json:"avatar,omitempty" db:"avatar"`
+	Signature string     `json:"signature,omitempty" db:"signature"`
+	Website   string     `json:"website,omitempty" db:"website"`
+	Location  string     `json:"location,omitempty" db:"location"`
+	Joined    time.Time  `json:"joined,omitempty" db:"created"`
+	LastSeen  *time.Time `json:"last_seen,omitempty" db:"last_seen"`
+	Posts     int64      `json:"posts,omitempty" db:"posts"`
+	Threads   int64      `json:"threads,omitempty" db:"threads"`
+	// Настройки приватности видны только владельцу профиля.
+	Privacy *Privacy `json:"privacy,omitempty"`
+	// Аккаунт удалён, профиль обезличен.
+	Deleted bool `json:"deleted,omitempty"`
+	// Активность в форуме; заполняется только в списке участников форума.
+	Activity *ForumActivity `json:"activity,omitempty"`
+}
+
+// ForumActivity сообщения участника в одном форуме.
+//
+//easyjson:json
+type ForumActivity struct {
+	Posts       int64     `json:"posts" db:"posts"`
+	Threads     int64     `json:"threads" db:"threads"`
+	FirstActive time.Time `json:"first_active" db:"first_active"`
+	LastActive  time.Time `json:"last_active" db:"last_active"`
+}
+
+// Privacy поля профиля, скрытые от всех, кроме владельца.
+//
+//easyjson:json
+type Privacy struct {
+	HideEmail    bool `json:"hide_email,omitempty" db:"hide_email"`
+	HideWebsite  bool `json:"hide_website,omitempty" db:"hide_website"`
+	HideLocation bool `json:"hide_location,omitempty" db:"hide_location"`
+	HideLastSeen bool `json:"hide_last_seen,omitempty" db:"hide_last_seen"`
+	HideCounts   bool `json:"hide_counts,omitempty" db:"hide_counts"`
+}
+
+// UserUpdate частичное изменение профиля: отсутствующие в запросе поля не
+// меняются.
+//
+//easyjson:json
+type UserUpdate struct {
+	Fullname  *string        `json:"fullname"`
+	About     *string        `json:"about"`
+	Email     *string        `json:"email"`
+	Signature *string        `json:"signature"`
+	Website   *string        `json:"website"`
+	Location  *string        `json:"location"`
+	Privacy   *PrivacyUpdate `json:"privacy"`
+}
+
+//easyjson:json
+type PrivacyUpdate struct {
+	HideEmail    *bool `json:"hide_email"`
+	HideWebsite  *bool `json:"hide_website"`
+	HideLocation *bool `json:"hide_location"`
+	HideLastSeen *bool `json:"hide_last_seen"`
+	HideCounts   *bool `json:"hide_counts"`
+}
+
+// Rename новый никнейм пользователя.
+//
+//easyjson:json
+type Rename struct {
+	Nickname string `json:"nickname"`
+}
+
+// ExportedVote голос пользователя в архиве его данных.
+//
+//easyjson:json
+type ExportedVote struct {
+	Thread uint64 `json:"thread"`
+	Voice  int    `json:"voice"`
+}
+
+//easyjson:json
+type ExportedVoteList []ExportedVote
+
+// UserExport все данные пользователя для выгрузки.
+type UserExport struct {
+	Profile *User
+	Posts   PostList
+	Threads ThreadList
+	Votes   ExportedVoteList
+}
+
+// ForumUserDrift расхождение записи forum_users с профилем пользователя и его
+// сообщениями: stale, missing или orphaned.
+type ForumUserDrift struct {
+	Forum    string
+	Nickname string
+	Kind     string
+}
+
+// ForumUsersRepair количество исправленных записей forum_users по видам
+// расхождений.
+type ForumUsersRepair struct {
+	Stale    int64
+	Missing  int64
+	Orphaned int64
 }
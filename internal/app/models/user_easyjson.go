@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -17,7 +18,199 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson9e1087fdDecodeDBForumInternalAppModels(in *jlexer.Lexer, out *UserList) {
+func easyjson9e1087fdDecodeDBForumInternalAppModels(in *jlexer.Lexer, out *UserUpdate) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "fullname":
+			if in.IsNull() {
+				in.Skip()
+				out.Fullname = nil
+			} else {
+				if out.Fullname == nil {
+					out.Fullname = new(string)
+				}
+				*out.Fullname = string(in.String())
+			}
+		case "about":
+			if in.IsNull() {
+				in.Skip()
+				out.About = nil
+			} else {
+				if out.About == nil {
+					out.About = new(string)
+				}
+				*out.About = string(in.String())
+			}
+		case "email":
+			if in.IsNull() {
+				in.Skip()
+				out.Email = nil
+			} else {
+				if out.Email == nil {
+					out.Email = new(string)
+				}
+				*out.Email = string(in.String())
+			}
+		case "signature":
+			if in.IsNull() {
+				in.Skip()
+				out.Signature = nil
+			} else {
+				if out.Signature == nil {
+					out.Signature = new(string)
+				}
+				*out.Signature = string(in.String())
+			}
+		case "website":
+			if in.IsNull() {
+				in.Skip()
+				out.Website = nil
+			} else {
+				if out.Website == nil {
+					out.Website = new(string)
+				}
+				*out.Website = string(in.String())
+			}
+		case "location":
+			if in.IsNull() {
+				in.Skip()
+				out.Location = nil
+			} else {
+				if out.Location == nil {
+					out.Location = new(string)
+				}
+				*out.Location = string(in.String())
+			}
+		case "privacy":
+			if in.IsNull() {
+				in.Skip()
+				out.Privacy = nil
+			} else {
+				if out.Privacy == nil {
+					out.Privacy = new(PrivacyUpdate)
+				}
+				(*out.Privacy).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels(out *jwriter.Writer, in UserUpdate) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"fullname\":"
+		out.RawString(prefix[1:])
+		if in.Fullname == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Fullname))
+		}
+	}
+	{
+		const prefix string = ",\"about\":"
+		out.RawString(prefix)
+		if in.About == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.About))
+		}
+	}
+	{
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		if in.Email == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Email))
+		}
+	}
+	{
+		const prefix string = ",\"signature\":"
+		out.RawString(prefix)
+		if in.Signature == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Signature))
+		}
+	}
+	{
+		const prefix string = ",\"website\":"
+		out.RawString(prefix)
+		if in.Website == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Website))
+		}
+	}
+	{
+		const prefix string = ",\"location\":"
+		out.RawString(prefix)
+		if in.Location == nil {
+			out.RawString("null")
+		} else {
+			out.String(string(*in.Location))
+		}
+	}
+	{
+		const prefix string = ",\"privacy\":"
+		out.RawString(prefix)
+		if in.Privacy == nil {
+			out.RawString("null")
+		} else {
+			(*in.Privacy).MarshalEasyJSON(out)
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserUpdate) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserUpdate) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserUpdate) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserUpdate) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels1(in *jlexer.Lexer, out *UserList) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -26,7 +219,7 @@ func easyjson9e1087fdDecodeDBForumInternalAppModels(in *jlexer.Lexer, out *UserL
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(UserList, 0, 1)
+				*out = make(UserList, 0, 0)
 			} else {
 				*out = UserList{}
 			}
@@ -45,7 +238,7 @@ func easyjson9e1087fdDecodeDBForumInternalAppModels(in *jlexer.Lexer, out *UserL
 		in.Consumed()
 	}
 }
-func easyjson9e1087fdEncodeDBForumInternalAppModels(out *jwriter.Writer, in UserList) {
+func easyjson9e1087fdEncodeDBForumInternalAppModels1(out *jwriter.Writer, in UserList) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -63,27 +256,27 @@ func easyjson9e1087fdEncodeDBForumInternalAppModels(out *jwriter.Writer, in User
 // MarshalJSON supports json.Marshaler interface
 func (v UserList) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9e1087fdEncodeDBForumInternalAppModels(&w, v)
+	easyjson9e1087fdEncodeDBForumInternalAppModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v UserList) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9e1087fdEncodeDBForumInternalAppModels(w, v)
+	easyjson9e1087fdEncodeDBForumInternalAppModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *UserList) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9e1087fdDecodeDBForumInternalAppModels(&r, v)
+	easyjson9e1087fdDecodeDBForumInternalAppModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *UserList) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9e1087fdDecodeDBForumInternalAppModels(l, v)
+	easyjson9e1087fdDecodeDBForumInternalAppModels1(l, v)
 }
-func easyjson9e1087fdDecodeDBForumInternalAppModels1(in *jlexer.Lexer, out *User) {
+func easyjson9e1087fdDecodeDBForumInternalAppModels2(in *jlexer.Lexer, out *User) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -110,6 +303,58 @@ func easyjson9e1087fdDecodeDBForumInternalAppModels1(in *jlexer.Lexer, out *User
 			out.About = string(in.String())
 		case "email":
 			out.Email = string(in.String())
+		case "reputation":
+			out.Reputation = int64(in.Int64())
+		case "avatar":
+			out.Avatar = string(in.String())
+		case "signature":
+			out.Signature = string(in.String())
+		case "website":
+			out.Website = string(in.String())
+		case "location":
+			out.Location = string(in.String())
+		case "joined":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Joined).UnmarshalJSON(data))
+			}
+		case "last_seen":
+			if in.IsNull() {
+				in.Skip()
+				out.LastSeen = nil
+			} else {
+				if out.LastSeen == nil {
+					out.LastSeen = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastSeen).UnmarshalJSON(data))
+				}
+			}
+		case "posts":
+			out.Posts = int64(in.Int64())
+		case "threads":
+			out.Threads = int64(in.Int64())
+		case "privacy":
+			if in.IsNull() {
+				in.Skip()
+				out.Privacy = nil
+			} else {
+				if out.Privacy == nil {
+					out.Privacy = new(Privacy)
+				}
+				(*out.Privacy).UnmarshalEasyJSON(in)
+			}
+		case "deleted":
+			out.Deleted = bool(in.Bool())
+		case "activity":
+			if in.IsNull() {
+				in.Skip()
+				out.Activity = nil
+			} else {
+				if out.Activity == nil {
+					out.Activity = new(ForumActivity)
+				}
+				(*out.Activity).UnmarshalEasyJSON(in)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -120,7 +365,7 @@ func easyjson9e1087fdDecodeDBForumInternalAppModels1(in *jlexer.Lexer, out *User
 		in.Consumed()
 	}
 }
-func easyjson9e1087fdEncodeDBForumInternalAppModels1(out *jwriter.Writer, in User) {
+func easyjson9e1087fdEncodeDBForumInternalAppModels2(out *jwriter.Writer, in User) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -160,29 +405,714 @@ func easyjson9e1087fdEncodeDBForumInternalAppModels1(out *jwriter.Writer, in Use
 		}
 		out.String(string(in.Email))
 	}
+	if in.Reputation != 0 {
+		const prefix string = ",\"reputation\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Reputation))
+	}
+	if in.Avatar != "" {
+		const prefix string = ",\"avatar\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Avatar))
+	}
+	if in.Signature != "" {
+		const prefix string = ",\"signature\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Signature))
+	}
+	if in.Website != "" {
+		const prefix string = ",\"website\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Website))
+	}
+	if in.Location != "" {
+		const prefix string = ",\"location\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Location))
+	}
+	if true {
+		const prefix string = ",\"joined\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((in.Joined).MarshalJSON())
+	}
+	if in.LastSeen != nil {
+		const prefix string = ",\"last_seen\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.LastSeen).MarshalJSON())
+	}
+	if in.Posts != 0 {
+		const prefix string = ",\"posts\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Posts))
+	}
+	if in.Threads != 0 {
+		const prefix string = ",\"threads\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Threads))
+	}
+	if in.Privacy != nil {
+		const prefix string = ",\"privacy\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Privacy).MarshalEasyJSON(out)
+	}
+	if in.Deleted {
+		const prefix string = ",\"deleted\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Deleted))
+	}
+	if in.Activity != nil {
+		const prefix string = ",\"activity\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Activity).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v User) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9e1087fdEncodeDBForumInternalAppModels1(&w, v)
+	easyjson9e1087fdEncodeDBForumInternalAppModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v User) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9e1087fdEncodeDBForumInternalAppModels1(w, v)
+	easyjson9e1087fdEncodeDBForumInternalAppModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *User) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9e1087fdDecodeDBForumInternalAppModels1(&r, v)
+	easyjson9e1087fdDecodeDBForumInternalAppModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9e1087fdDecodeDBForumInternalAppModels1(l, v)
+	easyjson9e1087fdDecodeDBForumInternalAppModels2(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels3(in *jlexer.Lexer, out *Rename) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels3(out *jwriter.Writer, in Rename) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Rename) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Rename) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Rename) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Rename) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels3(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels4(in *jlexer.Lexer, out *PrivacyUpdate) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "hide_email":
+			if in.IsNull() {
+				in.Skip()
+				out.HideEmail = nil
+			} else {
+				if out.HideEmail == nil {
+					out.HideEmail = new(bool)
+				}
+				*out.HideEmail = bool(in.Bool())
+			}
+		case "hide_website":
+			if in.IsNull() {
+				in.Skip()
+				out.HideWebsite = nil
+			} else {
+				if out.HideWebsite == nil {
+					out.HideWebsite = new(bool)
+				}
+				*out.HideWebsite = bool(in.Bool())
+			}
+		case "hide_location":
+			if in.IsNull() {
+				in.Skip()
+				out.HideLocation = nil
+			} else {
+				if out.HideLocation == nil {
+					out.HideLocation = new(bool)
+				}
+				*out.HideLocation = bool(in.Bool())
+			}
+		case "hide_last_seen":
+			if in.IsNull() {
+				in.Skip()
+				out.HideLastSeen = nil
+			} else {
+				if out.HideLastSeen == nil {
+					out.HideLastSeen = new(bool)
+				}
+				*out.HideLastSeen = bool(in.Bool())
+			}
+		case "hide_counts":
+			if in.IsNull() {
+				in.Skip()
+				out.HideCounts = nil
+			} else {
+				if out.HideCounts == nil {
+					out.HideCounts = new(bool)
+				}
+				*out.HideCounts = bool(in.Bool())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels4(out *jwriter.Writer, in PrivacyUpdate) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"hide_email\":"
+		out.RawString(prefix[1:])
+		if in.HideEmail == nil {
+			out.RawString("null")
+		} else {
+			out.Bool(bool(*in.HideEmail))
+		}
+	}
+	{
+		const prefix string = ",\"hide_website\":"
+		out.RawString(prefix)
+		if in.HideWebsite == nil {
+			out.RawString("null")
+		} else {
+			out.Bool(bool(*in.HideWebsite))
+		}
+	}
+	{
+		const prefix string = ",\"hide_location\":"
+		out.RawString(prefix)
+		if in.HideLocation == nil {
+			out.RawString("null")
+		} else {
+			out.Bool(bool(*in.HideLocation))
+		}
+	}
+	{
+		const prefix string = ",\"hide_last_seen\":"
+		out.RawString(prefix)
+		if in.HideLastSeen == nil {
+			out.RawString("null")
+		} else {
+			out.Bool(bool(*in.HideLastSeen))
+		}
+	}
+	{
+		const prefix string = ",\"hide_counts\":"
+		out.RawString(prefix)
+		if in.HideCounts == nil {
+			out.RawString("null")
+		} else {
+			out.Bool(bool(*in.HideCounts))
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PrivacyUpdate) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PrivacyUpdate) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PrivacyUpdate) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PrivacyUpdate) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels4(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels5(in *jlexer.Lexer, out *Privacy) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "hide_email":
+			out.HideEmail = bool(in.Bool())
+		case "hide_website":
+			out.HideWebsite = bool(in.Bool())
+		case "hide_location":
+			out.HideLocation = bool(in.Bool())
+		case "hide_last_seen":
+			out.HideLastSeen = bool(in.Bool())
+		case "hide_counts":
+			out.HideCounts = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels5(out *jwriter.Writer, in Privacy) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.HideEmail {
+		const prefix string = ",\"hide_email\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Bool(bool(in.HideEmail))
+	}
+	if in.HideWebsite {
+		const prefix string = ",\"hide_website\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.HideWebsite))
+	}
+	if in.HideLocation {
+		const prefix string = ",\"hide_location\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.HideLocation))
+	}
+	if in.HideLastSeen {
+		const prefix string = ",\"hide_last_seen\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.HideLastSeen))
+	}
+	if in.HideCounts {
+		const prefix string = ",\"hide_counts\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.HideCounts))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Privacy) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Privacy) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Privacy) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Privacy) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels5(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels6(in *jlexer.Lexer, out *ForumActivity) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "posts":
+			out.Posts = int64(in.Int64())
+		case "threads":
+			out.Threads = int64(in.Int64())
+		case "first_active":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.FirstActive).UnmarshalJSON(data))
+			}
+		case "last_active":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.LastActive).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels6(out *jwriter.Writer, in ForumActivity) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Posts))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Threads))
+	}
+	{
+		const prefix string = ",\"first_active\":"
+		out.RawString(prefix)
+		out.Raw((in.FirstActive).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"last_active\":"
+		out.RawString(prefix)
+		out.Raw((in.LastActive).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumActivity) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumActivity) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumActivity) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumActivity) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels6(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels7(in *jlexer.Lexer, out *ExportedVoteList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ExportedVoteList, 0, 4)
+			} else {
+				*out = ExportedVoteList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 ExportedVote
+			(v4).UnmarshalEasyJSON(in)
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels7(out *jwriter.Writer, in ExportedVoteList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			(v6).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ExportedVoteList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ExportedVoteList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ExportedVoteList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ExportedVoteList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels7(l, v)
+}
+func easyjson9e1087fdDecodeDBForumInternalAppModels8(in *jlexer.Lexer, out *ExportedVote) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "thread":
+			out.Thread = uint64(in.Uint64())
+		case "voice":
+			out.Voice = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDBForumInternalAppModels8(out *jwriter.Writer, in ExportedVote) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix[1:])
+		out.Uint64(uint64(in.Thread))
+	}
+	{
+		const prefix string = ",\"voice\":"
+		out.RawString(prefix)
+		out.Int(int(in.Voice))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ExportedVote) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDBForumInternalAppModels8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ExportedVote) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDBForumInternalAppModels8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ExportedVote) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDBForumInternalAppModels8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ExportedVote) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDBForumInternalAppModels8(l, v)
 }
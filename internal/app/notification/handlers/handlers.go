@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	notificationUseCase "DBForum/internal/app/notification/usecase"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase notificationUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase notificationUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+func (h *Handlers) GetNotifications(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	// since - идентификатор уведомления, после которого будут выводиться
+	// записи (уведомление с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+	query := models.NotificationQuery{
+		// Только непрочитанные.
+		Unread: ctx.QueryArgs().GetBool("unread"),
+		Limit:  page.Limit,
+		Since:  page.SinceID,
+		Desc:   page.Desc,
+	}
+
+	var notifications models.NotificationList
+	notifications, err := h.useCase.GetNotifications(nickname, query, middleware.Actor(ctx))
+	if h.respondNotificationErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, notifications)
+}
+
+func (h *Handlers) GetUnreadCount(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	count, err := h.useCase.GetUnreadCount(nickname, middleware.Actor(ctx))
+	if h.respondNotificationErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, count)
+}
+
+// MarkRead отмечает прочитанными уведомления из {"ids": [...]} и отвечает
+// оставшимся числом непрочитанных.
+func (h *Handlers) MarkRead(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	var read models.NotificationRead
+	if err := easyjson.Unmarshal(ctx.PostBody(), &read); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	count, err := h.useCase.MarkRead(nickname, read.IDs, middleware.Actor(ctx))
+	if h.respondNotificationErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, count)
+}
+
+func (h *Handlers) MarkAllRead(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	count, err := h.useCase.MarkAllRead(nickname, middleware.Actor(ctx))
+	if h.respondNotificationErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, count)
+}
+
+func (h *Handlers) respondNotificationErr(ctx *fasthttp.RequestCtx, nickname string, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't read notifications of another user: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
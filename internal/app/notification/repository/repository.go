@@ -0,0 +1,167 @@
+package repository
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+)
+
+const (
+	notificationColumns = "id, type, actor, post_id, thread_id, forum_slug, created, read"
+
+	selectNotifications = "SELECT " + notificationColumns + " FROM dbforum.notifications " +
+		"WHERE nickname = $1 AND (NOT $2 OR read IS NULL) AND ($3::bigint = 0 OR id > $3) " +
+		"ORDER BY id LIMIT $4"
+
+	selectNotificationsDesc = "SELECT " + notificationColumns + " FROM dbforum.notifications " +
+		"WHERE nickname = $1 AND (NOT $2 OR read IS NULL) AND ($3::bigint = 0 OR id < $3) " +
+		"ORDER BY id DESC LIMIT $4"
+
+	selectNotifiedUser = "SELECT nickname FROM dbforum.users WHERE nickname = $1"
+
+	markNotificationsRead = "UPDATE dbforum.notifications SET read = now() " +
+		"WHERE nickname = $1 AND id = ANY($2) AND read IS NULL"
+
+	markAllNotificationsRead = "UPDATE dbforum.notifications SET read = now() " +
+		"WHERE nickname = $1 AND read IS NULL"
+
+	selectUnreadCounts = "SELECT type, COUNT(*) FROM dbforum.notifications " +
+		"WHERE nickname = $1 AND read IS NULL GROUP BY type"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetNotifications(nickname string, query models.NotificationQuery) ([]models.Notification, error) {
+	if err := r.userExists(nickname); err != nil {
+		return nil, err
+	}
+	name := "selectNotifications"
+	if query.Desc {
+		name = "selectNotificationsDesc"
+	}
+	rows, err := r.db.Query(name, nickname, query.Unread, query.Since, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var notifications []models.Notification
+	for rows.Next() {
+		n := models.Notification{}
+		err := rows.Scan(
+			&n.ID,
+			&n.Type,
+			&n.Actor,
+			&n.Post,
+			&n.Thread,
+			&n.Forum,
+			&n.Created,
+			&n.Read)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// MarkRead отмечает прочитанными уведомления ids пользователя. Чужие и уже
+// прочитанные уведомления не меняются.
+func (r *Repository) MarkRead(nickname string, ids []uint64) (int64, error) {
+	if err := r.userExists(nickname); err != nil {
+		return 0, err
+	}
+	// pgx v3 кодирует []int64, но не []uint64.
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id)
+	}
+	tag, err := r.db.Exec("markNotificationsRead", nickname, values)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *Repository) MarkAllRead(nickname string) (int64, error) {
+	if err := r.userExists(nickname); err != nil {
+		return 0, err
+	}
+	tag, err := r.db.Exec("markAllNotificationsRead", nickname)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *Repository) GetUnreadCount(nickname string) (models.NotificationCount, error) {
+	count := models.NotificationCount{ByType: map[string]int64{}}
+	if err := r.userExists(nickname); err != nil {
+		return count, err
+	}
+	rows, err := r.db.Query("selectUnreadCounts", nickname)
+	if err != nil {
+		return count, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind string
+		var n int64
+		if err := rows.Scan(&kind, &n); err != nil {
+			return count, err
+		}
+		count.ByType[kind] = n
+		count.Unread += n
+	}
+	return count, rows.Err()
+}
+
+func (r *Repository) userExists(nickname string) error {
+	var user string
+	err := r.db.QueryRow("selectNotifiedUser", nickname).Scan(&user)
+	if err == pgx.ErrNoRows {
+		return customErr.ErrUserNotFound
+	}
+	return err
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectNotifications", selectNotifications)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectNotificationsDesc", selectNotificationsDesc)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectNotifiedUser", selectNotifiedUser)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("markNotificationsRead", markNotificationsRead)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("markAllNotificationsRead", markAllNotificationsRead)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectUnreadCounts", selectUnreadCounts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
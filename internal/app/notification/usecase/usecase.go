@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	notificationRepo "DBForum/internal/app/notification/repository"
+	"strings"
+)
+
+type UseCase struct {
+	repo notificationRepo.Repository
+}
+
+func NewUseCase(repo notificationRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+// GetNotifications уведомления доступны только их получателю.
+func (u *UseCase) GetNotifications(nickname string, query models.NotificationQuery, actor models.Actor) ([]models.Notification, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return nil, customErr.ErrForbidden
+	}
+	notifications, err := u.repo.GetNotifications(nickname, query)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		return []models.Notification{}, nil
+	}
+	return notifications, nil
+}
+
+// MarkRead отмечает прочитанными уведомления ids и возвращает оставшиеся
+// непрочитанные.
+func (u *UseCase) MarkRead(nickname string, ids []uint64, actor models.Actor) (models.NotificationCount, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return models.NotificationCount{}, customErr.ErrForbidden
+	}
+	if _, err := u.repo.MarkRead(nickname, ids); err != nil {
+		return models.NotificationCount{}, err
+	}
+	return u.repo.GetUnreadCount(nickname)
+}
+
+func (u *UseCase) MarkAllRead(nickname string, actor models.Actor) (models.NotificationCount, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return models.NotificationCount{}, customErr.ErrForbidden
+	}
+	if _, err := u.repo.MarkAllRead(nickname); err != nil {
+		return models.NotificationCount{}, err
+	}
+	return u.repo.GetUnreadCount(nickname)
+}
+
+func (u *UseCase) GetUnreadCount(nickname string, actor models.Actor) (models.NotificationCount, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return models.NotificationCount{}, customErr.ErrForbidden
+	}
+	return u.repo.GetUnreadCount(nickname)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
 	"DBForum/internal/app/models"
 	postUseCase "DBForum/internal/app/post/usecase"
 	"errors"
@@ -32,7 +33,7 @@ func (h *Handlers) GetInfo(ctx *fasthttp.RequestCtx) {
 	// values: user/forum/thread
 	related := strings.Split(string(ctx.QueryArgs().Peek("related")), ",")
 
-	postInfo, err := h.useCase.GetPostInfoByID(id, related)
+	postInfo, err := h.useCase.GetPostInfoByID(id, related, middleware.Actor(ctx))
 
 	if errors.Is(err, customErr.ErrPostNotFound) {
 		resp := map[string]string{
@@ -60,7 +61,7 @@ func (h *Handlers) ChangeMessage(ctx *fasthttp.RequestCtx) {
 
 	post.ID = id
 	var err error
-	post, err = h.useCase.ChangeMessage(*post)
+	post, err = h.useCase.ChangeMessage(*post, middleware.Actor(ctx))
 	if errors.Is(err, customErr.ErrPostNotFound) {
 		resp := map[string]string{
 			"message": "Can't find post with id: " + strconv.FormatUint(id, 10),
@@ -68,6 +69,24 @@ func (h *Handlers) ChangeMessage(ctx *fasthttp.RequestCtx) {
 		httputils.RespondErr(ctx, http.StatusNotFound, resp)
 		return
 	}
+	if errors.Is(err, customErr.ErrEditWindowExpired) {
+		resp := map[string]string{
+			"code":    "edit_window_expired",
+			"message": "Edit window for post has passed: " + strconv.FormatUint(id, 10),
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't edit post of another user: " + strconv.FormatUint(id, 10),
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if httputils.RespondBanned(ctx, err) {
+		return
+	}
 	if err != nil {
 		log.Println(err)
 		httputils.Respond(ctx, http.StatusInternalServerError, post)
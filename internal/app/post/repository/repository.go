@@ -1,8 +1,13 @@
 package repository
 
 import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	banRepo "DBForum/internal/app/ban/repository"
+	blockRepo "DBForum/internal/app/block/repository"
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/models"
+	reportRepo "DBForum/internal/app/report/repository"
+	userRepo "DBForum/internal/app/user/repository"
 	"database/sql"
 	"fmt"
 	"github.com/go-openapi/strfmt"
@@ -13,28 +18,49 @@ import (
 )
 
 const (
+	postColumns = "id, author_nickname, forum_slug, thread_id, message, parent, is_edited, created, tree, edit_count, last_edited"
+
 	insertPost = `INSERT INTO dbforum.post(author_nickname, forum_slug, thread_id, parent, created, message)
 				VALUES ($1, $2, $3, $4, $5, $6)
 				RETURNING ID`
 
-	selectByThreadIDFlatDesc = "SELECT * FROM dbforum.post WHERE thread_id=$1 AND CASE WHEN $2 > 0 THEN id < $2 ELSE TRUE END ORDER BY id DESC LIMIT $3"
+	selectByThreadIDFlatDesc = "SELECT " + postColumns + " FROM dbforum.post WHERE thread_id=$1 AND NOT hidden AND CASE WHEN $2 > 0 THEN id < $2 ELSE TRUE END ORDER BY id DESC LIMIT $3"
+
+	selectByThreadIDFlat = "SELECT " + postColumns + " FROM dbforum.post WHERE thread_id=$1 AND NOT hidden AND CASE WHEN $2 > 0 THEN id > $2 ELSE TRUE END ORDER BY id LIMIT $3"
+
+	selectByThreadIDTreeDesc = "SELECT " + postColumns + " FROM dbforum.post WHERE thread_id=$1 AND NOT hidden AND CASE WHEN $2 > 0 THEN tree < (SELECT tree FROM dbforum.post WHERE id=$2) ELSE TRUE END ORDER BY tree DESC LIMIT $3"
+
+	selectByThreadIDTree = "SELECT " + postColumns + " FROM dbforum.post WHERE thread_id=$1 AND NOT hidden AND CASE WHEN $2 > 0 THEN tree > (SELECT tree FROM dbforum.post WHERE id=$2) ELSE TRUE END ORDER BY tree LIMIT $3"
+
+	selectByThreadIDParentTreeDesc = "SELECT " + postColumns + " FROM dbforum.post WHERE NOT hidden AND tree[1] IN (SELECT id FROM dbforum.post WHERE thread_id = $1 AND parent = 0 AND NOT hidden AND CASE WHEN $3 > 0 THEN tree[1] < (SELECT tree[1] FROM dbforum.post WHERE id=$3) ELSE TRUE END ORDER BY id DESC LIMIT $2) ORDER BY tree[1] DESC, tree, id"
 
-	selectByThreadIDFlat = "SELECT * FROM dbforum.post WHERE thread_id=$1 AND CASE WHEN $2 > 0 THEN id > $2 ELSE TRUE END ORDER BY id LIMIT $3"
+	selectByThreadIDParentTree = "SELECT " + postColumns + " FROM dbforum.post WHERE NOT hidden AND tree[1] IN (SELECT id FROM dbforum.post WHERE thread_id = $1 AND parent = 0 AND NOT hidden AND CASE WHEN $3 > 0 THEN tree[1] > (SELECT tree[1] FROM dbforum.post WHERE id=$3) ELSE TRUE END ORDER BY id LIMIT $2) ORDER BY tree, id"
 
-	selectByThreadIDTreeDesc = "SELECT * FROM dbforum.post WHERE thread_id=$1 AND CASE WHEN $2 > 0 THEN tree < (SELECT tree FROM dbforum.post WHERE id=$2) ELSE TRUE END ORDER BY tree DESC LIMIT $3"
+	selectPostsByAuthor = "SELECT " + postColumns + " FROM dbforum.post " +
+		"WHERE author_nickname = $1 AND NOT hidden " +
+		"AND ($2::text = '' OR forum_slug = $2::citext) " +
+		"AND ($3::bigint = 0 OR (created, id) > (SELECT created, id FROM dbforum.post WHERE id = $3)) " +
+		"ORDER BY created, id LIMIT $4"
 
-	selectByThreadIDTree = "SELECT * FROM dbforum.post WHERE thread_id=$1 AND CASE WHEN $2 > 0 THEN tree > (SELECT tree FROM dbforum.post WHERE id=$2) ELSE TRUE END ORDER BY tree LIMIT $3"
+	selectPostsByAuthorDesc = "SELECT " + postColumns + " FROM dbforum.post " +
+		"WHERE author_nickname = $1 AND NOT hidden " +
+		"AND ($2::text = '' OR forum_slug = $2::citext) " +
+		"AND ($3::bigint = 0 OR (created, id) < (SELECT created, id FROM dbforum.post WHERE id = $3)) " +
+		"ORDER BY created DESC, id DESC LIMIT $4"
 
-	selectByThreadIDParentTreeDesc = "SELECT * FROM dbforum.post WHERE tree[1] IN (SELECT id FROM dbforum.post WHERE thread_id = $1 AND parent = 0 AND CASE WHEN $3 > 0 THEN tree[1] < (SELECT tree[1] FROM dbforum.post WHERE id=$3) ELSE TRUE END ORDER BY id DESC LIMIT $2) ORDER BY tree[1] DESC, tree, id"
+	// Выгрузка пользователя включает и его скрытые сообщения.
+	selectPostsForExport = "SELECT " + postColumns + ", hidden FROM dbforum.post " +
+		"WHERE author_nickname = $1 AND id > $2 ORDER BY id LIMIT $3"
 
-	selectByThreadIDParentTree = "SELECT * FROM dbforum.post WHERE tree[1] IN (SELECT id FROM dbforum.post WHERE thread_id = $1 AND parent = 0  AND CASE WHEN $3 > 0 THEN tree[1] > (SELECT tree[1] FROM dbforum.post WHERE id=$3) ELSE TRUE END ORDER BY id LIMIT $2) ORDER BY tree, id"
+	selectPostByID = "SELECT " + postColumns + ", hidden FROM dbforum.post WHERE id=$1"
 
-	selectPostByID = "SELECT * FROM dbforum.post WHERE id=$1"
+	selectPostEditInfo = "SELECT p.author_nickname, p.forum_slug, p.created, u.password <> '' " +
+		"FROM dbforum.post AS p JOIN dbforum.users AS u ON u.nickname = p.author_nickname WHERE p.id = $1"
 
 	updatePost = `UPDATE dbforum.post SET message=COALESCE(NULLIF($1, ''), message),
                 	is_edited = CASE WHEN $1 = '' OR message = $1 THEN is_edited ELSE true END
 					WHERE id=$2 
-					RETURNING id, author_nickname, forum_slug, thread_id, message, parent, is_edited, created`
+					RETURNING id, author_nickname, forum_slug, thread_id, message, parent, is_edited, created, edit_count, last_edited`
 )
 
 type Repository struct {
@@ -47,8 +73,8 @@ func NewRepo(db *pgx.ConnPool) *Repository {
 	}
 }
 
-func (r *Repository) CreatePosts(idOrSlug string, posts []models.Post) ([]models.Post, error) {
-	tx, err := r.db.Begin()
+func (r *Repository) CreatePosts(idOrSlug string, posts []models.Post, actor models.Actor) ([]models.Post, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +140,7 @@ func (r *Repository) CreatePosts(idOrSlug string, posts []models.Post) ([]models
 	}
 
 	created := strfmt.DateTime(time.Now())
-	query := "INSERT INTO dbforum.post(author_nickname, forum_slug, thread_id, parent, created, message) VALUES "
+	query := "INSERT INTO dbforum.post(author_nickname, forum_slug, thread_id, parent, created, message, hidden) VALUES "
 	var args []interface{}
 	for i, post := range posts {
 		posts[i].Created = created
@@ -131,19 +157,30 @@ func (r *Repository) CreatePosts(idOrSlug string, posts []models.Post) ([]models
 				return nil, errors.Wrap(customErr.ErrUserNotFound, post.Author)
 			}
 			row.Close()
+			if err = banRepo.CheckBan(tx, post.Author, forumSlug); err != nil {
+				_ = tx.Rollback()
+				return nil, err
+			}
+			// Нельзя отвечать тому, кто заблокировал автора.
+			if post.Parent != 0 {
+				if err = blockRepo.CheckReply(tx, post.Parent, post.Author); err != nil {
+					_ = tx.Rollback()
+					return nil, err
+				}
+			}
 		} else {
 			_ = tx.Rollback()
 			return nil, nil
 		}
 
-		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)",
-			i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6)
+		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7)
 		if i != len(posts)-1 {
 			query += ","
 		} else {
 			query += " RETURNING ID"
 		}
-		args = append(args, post.Author, forumSlug, threadID, post.Parent, created, post.Message)
+		args = append(args, post.Author, forumSlug, threadID, post.Parent, created, post.Message, post.Held)
 	}
 	rows, err := tx.Query(query, args...)
 	if err != nil {
@@ -160,6 +197,15 @@ func (r *Repository) CreatePosts(idOrSlug string, posts []models.Post) ([]models
 		index++
 	}
 	rows.Close()
+	for _, post := range posts {
+		if !post.Held {
+			continue
+		}
+		if err := reportRepo.Hold(tx, "post", post.ID, forumSlug, post.Author, post.HoldReason); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
 	if err := tx.Commit(); err != nil {
 		_ = tx.Rollback()
 		return nil, err
@@ -251,8 +297,65 @@ func (r *Repository) GetPosts(idOrSlug string, limit int64, since int64, desc bo
 			&p.Parent,
 			&p.IsEdited,
 			&p.Created,
-			&p.Tree)
+			&p.Tree,
+			&p.EditCount,
+			&p.LastEdited)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	rows.Close()
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *Repository) GetUserPosts(nickname string, forumSlug string, limit int, since uint64, desc bool) ([]models.Post, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query("selectPostAuthor", nickname)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if !rows.Next() {
+		rows.Close()
+		_ = tx.Rollback()
+		return nil, customErr.ErrUserNotFound
+	}
+	rows.Close()
+	if desc {
+		rows, err = tx.Query("selectPostsByAuthorDesc", nickname, forumSlug, since, limit)
+	} else {
+		rows, err = tx.Query("selectPostsByAuthor", nickname, forumSlug, since, limit)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var posts []models.Post
+	for rows.Next() {
+		p := models.Post{}
+		err := rows.Scan(
+			&p.ID,
+			&p.Author,
+			&p.Forum,
+			&p.Thread,
+			&p.Message,
+			&p.Parent,
+			&p.IsEdited,
+			&p.Created,
+			&p.Tree,
+			&p.EditCount,
+			&p.LastEdited)
 		if err != nil {
+			rows.Close()
 			_ = tx.Rollback()
 			return nil, err
 		}
@@ -266,6 +369,38 @@ func (r *Repository) GetPosts(idOrSlug string, limit int64, since int64, desc bo
 	return posts, nil
 }
 
+// ExportUserPosts сообщения пользователя для выгрузки по возрастанию id,
+// вместе со скрытыми.
+func (r *Repository) ExportUserPosts(nickname string, limit int, since uint64) ([]models.Post, error) {
+	rows, err := r.db.Query("selectPostsForExport", nickname, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var posts []models.Post
+	for rows.Next() {
+		p := models.Post{}
+		err := rows.Scan(
+			&p.ID,
+			&p.Author,
+			&p.Forum,
+			&p.Thread,
+			&p.Message,
+			&p.Parent,
+			&p.IsEdited,
+			&p.Created,
+			&p.Tree,
+			&p.EditCount,
+			&p.LastEdited,
+			&p.Held)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
+
 func Find(slice []string, val string) bool {
 	for _, item := range slice {
 		if item == val {
@@ -298,7 +433,10 @@ func (r *Repository) GetPostInfoByID(id uint64, related []string) (*models.PostI
 		&postInfo.Post.Parent,
 		&postInfo.Post.IsEdited,
 		&postInfo.Post.Created,
-		&postInfo.Post.Tree)
+		&postInfo.Post.Tree,
+		&postInfo.Post.EditCount,
+		&postInfo.Post.LastEdited,
+		&postInfo.Post.Held)
 	rows.Close()
 	if err != nil {
 		_ = tx.Rollback()
@@ -313,11 +451,7 @@ func (r *Repository) GetPostInfoByID(id uint64, related []string) (*models.PostI
 		}
 		if rows.Next() {
 			postInfo.Author = &models.User{}
-			err = rows.Scan(
-				&postInfo.Author.Nickname,
-				&postInfo.Author.Fullname,
-				&postInfo.Author.About,
-				&postInfo.Author.Email)
+			err = userRepo.ScanUser(rows, postInfo.Author)
 			if err != nil {
 				_ = tx.Rollback()
 				return nil, err
@@ -341,7 +475,9 @@ func (r *Repository) GetPostInfoByID(id uint64, related []string) (*models.PostI
 				&postInfo.Thread.Message,
 				&postInfo.Thread.Votes,
 				&postInfo.Thread.Slug,
-				&postInfo.Thread.Created)
+				&postInfo.Thread.Created,
+				&postInfo.Thread.EditCount,
+				&postInfo.Thread.LastEdited)
 			if err != nil {
 				_ = tx.Rollback()
 				return nil, err
@@ -379,8 +515,55 @@ func (r *Repository) GetPostInfoByID(id uint64, related []string) (*models.PostI
 	return &postInfo, nil
 }
 
-func (r *Repository) ChangePost(post *models.Post) (models.Post, error) {
-	err := r.db.QueryRow("updatePost", &post.Message, &post.ID).Scan(
+func (r *Repository) GetPostEditInfo(id uint64) (models.EditInfo, error) {
+	var info models.EditInfo
+	err := r.db.QueryRow("selectPostEditInfo", id).Scan(
+		&info.Author,
+		&info.Forum,
+		&info.Created,
+		&info.Protected)
+	if err == pgx.ErrNoRows {
+		return models.EditInfo{}, customErr.ErrPostNotFound
+	}
+	if err != nil {
+		return models.EditInfo{}, err
+	}
+	return info, nil
+}
+
+// ChangePost изменяет сообщение от имени actor; для неаутентифицированного
+// запроса бан проверяется у автора сообщения.
+func (r *Repository) ChangePost(post *models.Post, actor models.Actor) (models.Post, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return models.Post{}, err
+	}
+	var author, forumSlug string
+	rows, err := tx.Query("selectPostAuthorAndForum", post.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return models.Post{}, err
+	}
+	if !rows.Next() {
+		rows.Close()
+		_ = tx.Rollback()
+		return models.Post{}, customErr.ErrPostNotFound
+	}
+	err = rows.Scan(&author, &forumSlug)
+	rows.Close()
+	if err != nil {
+		_ = tx.Rollback()
+		return models.Post{}, err
+	}
+	editor := actor.Nickname
+	if editor == "" {
+		editor = author
+	}
+	if err = banRepo.CheckBan(tx, editor, forumSlug); err != nil {
+		_ = tx.Rollback()
+		return models.Post{}, err
+	}
+	err = tx.QueryRow("updatePost", &post.Message, &post.ID).Scan(
 		&post.ID,
 		&post.Author,
 		&post.Forum,
@@ -388,10 +571,17 @@ func (r *Repository) ChangePost(post *models.Post) (models.Post, error) {
 		&post.Message,
 		&post.Parent,
 		&post.IsEdited,
-		&post.Created)
+		&post.Created,
+		&post.EditCount,
+		&post.LastEdited)
 	if err != nil {
+		_ = tx.Rollback()
 		return models.Post{}, customErr.ErrPostNotFound
 	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return models.Post{}, err
+	}
 	return *post, nil
 }
 
@@ -430,6 +620,31 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
+	_, err = r.db.Prepare("selectPostsByAuthor", selectPostsByAuthor)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectPostsByAuthorDesc", selectPostsByAuthorDesc)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectPostsForExport", selectPostsForExport)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectPostAuthorAndForum", "SELECT author_nickname, forum_slug FROM dbforum.post WHERE id = $1")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectPostEditInfo", selectPostEditInfo)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Prepare("updatePost", updatePost)
 	if err != nil {
 		return err
@@ -1,11 +1,15 @@
 package usecase
 
 import (
+	"DBForum/internal/app/config"
+	customErr "DBForum/internal/app/errors"
 	forumRepository "DBForum/internal/app/forum/repository"
 	"DBForum/internal/app/models"
 	postRepository "DBForum/internal/app/post/repository"
+	roleUseCase "DBForum/internal/app/role/usecase"
 	threadRepository "DBForum/internal/app/thread/repository"
 	userRepository "DBForum/internal/app/user/repository"
+	userUseCase "DBForum/internal/app/user/usecase"
 )
 
 type UseCase struct {
@@ -13,30 +17,55 @@ type UseCase struct {
 	userRepo   userRepository.Repository
 	threadRepo threadRepository.Repository
 	forumRepo  forumRepository.Repository
+	editPolicy config.EditPolicy
 }
 
 func NewUseCase(postRepo postRepository.Repository,
 	userRepo userRepository.Repository,
 	threadRepo threadRepository.Repository,
-	forumRepo forumRepository.Repository) *UseCase {
+	forumRepo forumRepository.Repository,
+	editPolicy config.EditPolicy) *UseCase {
 	return &UseCase{
 		postRepo:   postRepo,
 		userRepo:   userRepo,
 		threadRepo: threadRepo,
 		forumRepo:  forumRepo,
+		editPolicy: editPolicy,
 	}
 }
 
-func (u *UseCase) GetPostInfoByID(id uint64, related []string) (models.PostInfo, error) {
+// GetPostInfoByID сообщение и связанные объекты; скрытые поля профиля автора
+// видны только ему самому. Скрытое или задержанное сообщение видят только
+// автор и модераторы форума, остальным оно не найдено.
+func (u *UseCase) GetPostInfoByID(id uint64, related []string, actor models.Actor) (models.PostInfo, error) {
 	postInfo, err := u.postRepo.GetPostInfoByID(id, related)
 	if err != nil {
 		return models.PostInfo{}, err
 	}
+	visibility := models.Visibility{
+		Author: postInfo.Post.Author,
+		Forum:  postInfo.Post.Forum,
+		Hidden: postInfo.Post.Held,
+	}
+	if !roleUseCase.CanView(actor, visibility) {
+		return models.PostInfo{}, customErr.ErrPostNotFound
+	}
+	if postInfo.Author != nil {
+		userUseCase.ApplyPrivacy(postInfo.Author, actor.Nickname)
+	}
 	return *postInfo, nil
 }
 
-func (u *UseCase) ChangeMessage(post models.Post) (*models.Post, error) {
-	post, err := u.postRepo.ChangePost(&post)
+// ChangeMessage изменяет сообщение с проверкой права на правку.
+func (u *UseCase) ChangeMessage(post models.Post, actor models.Actor) (*models.Post, error) {
+	info, err := u.postRepo.GetPostEditInfo(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := roleUseCase.CanEdit(actor, info, u.editPolicy.Window); err != nil {
+		return nil, err
+	}
+	post, err = u.postRepo.ChangePost(&post, actor)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	reportUseCase "DBForum/internal/app/report/usecase"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type Handlers struct {
+	useCase reportUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase reportUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+func (h *Handlers) ReportPost(ctx *fasthttp.RequestCtx) {
+	report, ok := h.parseReport(ctx)
+	if !ok {
+		return
+	}
+	report.Target, _ = strconv.ParseUint(ctx.UserValue("id").(string), 10, 64)
+
+	created, err := h.useCase.ReportPost(report, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrPostNotFound) {
+		resp := map[string]string{
+			"message": "Can't find post with id: " + ctx.UserValue("id").(string),
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if h.respondReportErr(ctx, report, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusCreated, created)
+}
+
+func (h *Handlers) ReportThread(ctx *fasthttp.RequestCtx) {
+	report, ok := h.parseReport(ctx)
+	if !ok {
+		return
+	}
+	idOrSlug := ctx.UserValue("slug_or_id").(string)
+
+	created, err := h.useCase.ReportThread(report, idOrSlug, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrThreadNotFound) {
+		resp := map[string]string{
+			"message": "Can't find thread by slug or id: " + idOrSlug,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if h.respondReportErr(ctx, report, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusCreated, created)
+}
+
+// GetQueue очередь модерации форума: объекты с открытыми жалобами.
+func (h *Handlers) GetQueue(ctx *fasthttp.RequestCtx) {
+	forumSlug := ctx.UserValue("slug").(string)
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceNone})
+	if !ok {
+		return
+	}
+
+	var queue models.ModerationQueue
+	queue, err := h.useCase.GetModerationQueue(forumSlug, page.Limit)
+	if errors.Is(err, customErr.ErrForumNotFound) {
+		resp := map[string]string{
+			"message": "Can't find forum by slug: " + forumSlug,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, queue)
+}
+
+// Resolve применяет решение модератора (dismiss, hide, delete, ban) ко
+// всем жалобам на объект.
+func (h *Handlers) Resolve(ctx *fasthttp.RequestCtx) {
+	forumSlug := ctx.UserValue("slug").(string)
+	var action models.ModerationAction
+	if err := easyjson.Unmarshal(ctx.PostBody(), &action); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	result, err := h.useCase.Resolve(forumSlug, action, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrForumNotFound) {
+		resp := map[string]string{
+			"message": "Can't find forum by slug: " + forumSlug,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrReportNotFound) {
+		resp := map[string]string{
+			"message": "No open reports on " + action.Type + " " + strconv.FormatUint(action.Target, 10),
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrUnknownModerationAction) {
+		resp := map[string]string{
+			"message": "Unknown moderation action: " + action.Action + " on " + action.Type,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrInvalidBanPeriod) {
+		resp := map[string]string{
+			"message": "Ban end must be in the future",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, result)
+}
+
+// parseReport читает жалобу и привязывает её автора к вызывающему.
+func (h *Handlers) parseReport(ctx *fasthttp.RequestCtx) (models.Report, bool) {
+	var report models.Report
+	if err := easyjson.Unmarshal(ctx.PostBody(), &report); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return report, false
+	}
+	reporter, ok := middleware.BindAuthor(ctx, report.Reporter)
+	if !ok {
+		middleware.RespondAuthorMismatch(ctx, report.Reporter)
+		return report, false
+	}
+	report.Reporter = reporter
+	return report, true
+}
+
+func (h *Handlers) respondReportErr(ctx *fasthttp.RequestCtx, report models.Report, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + report.Reporter,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrReportExists) {
+		resp := map[string]string{
+			"message": "Already reported by " + report.Reporter,
+		}
+		httputils.RespondErr(ctx, http.StatusConflict, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
@@ -0,0 +1,452 @@
+package repository
+
+import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	banRepo "DBForum/internal/app/ban/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+	"strconv"
+)
+
+const (
+	selectReportedPost = "SELECT id, forum_slug, author_nickname FROM dbforum.post WHERE id = $1"
+
+	selectReportedThreadByID = "SELECT id, forum_slug, author_nickname FROM dbforum.thread WHERE id = $1"
+
+	selectReportedThreadBySlug = "SELECT id, forum_slug, author_nickname FROM dbforum.thread WHERE slug = $1"
+
+	insertReport = `INSERT INTO dbforum.reports(target_type, target_id, forum_slug, author_nickname, reporter, reason)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id, reporter, created`
+
+	// Задержанную фильтром запись в очередь ставит жалоба без автора.
+	insertHeldReport = `INSERT INTO dbforum.reports(target_type, target_id, forum_slug, author_nickname, reporter, reason)
+				VALUES ($1, $2, $3, $4, NULL, $5)`
+
+	selectReportForumSlug = "SELECT slug FROM dbforum.forum WHERE slug = $1"
+
+	// Сначала объекты с наибольшим числом жалоб, при равенстве - дольше
+	// всех ожидающие решения.
+	selectModerationQueue = `SELECT target_type, target_id, forum_slug, author_nickname, COUNT(*),
+					array_remove(array_agg(reason ORDER BY created), ''), MIN(created), MAX(created)
+				FROM dbforum.reports
+				WHERE forum_slug = $1 AND resolved IS NULL
+				GROUP BY target_type, target_id, forum_slug, author_nickname
+				ORDER BY COUNT(*) DESC, MIN(created)
+				LIMIT $2`
+
+	selectOpenReportAuthor = "SELECT author_nickname FROM dbforum.reports " +
+		"WHERE target_type = $1 AND target_id = $2 AND forum_slug = $3 AND resolved IS NULL LIMIT 1"
+
+	resolveReports = "UPDATE dbforum.reports SET resolved = now(), resolution = $3, resolved_by = $4 " +
+		"WHERE target_type = $1 AND target_id = $2 AND resolved IS NULL"
+
+	// Жалобы на сообщения удаляемой ветки закрываются вместе с ней.
+	resolveThreadPostReports = "UPDATE dbforum.reports SET resolved = now(), resolution = $2, resolved_by = $3 " +
+		"WHERE target_type = 'post' AND resolved IS NULL " +
+		"AND target_id IN (SELECT id FROM dbforum.post WHERE thread_id = $1)"
+
+	hidePost = "UPDATE dbforum.post SET hidden = true WHERE id = $1"
+
+	hideThread = "UPDATE dbforum.thread SET hidden = true WHERE id = $1"
+
+	showPost = "UPDATE dbforum.post SET hidden = false WHERE id = $1"
+
+	showThread = "UPDATE dbforum.thread SET hidden = false WHERE id = $1"
+
+	// Жалобы на ответы удаляемого сообщения закрываются вместе с ним.
+	resolveSubtreeReports = "UPDATE dbforum.reports SET resolved = now(), resolution = $2, resolved_by = $3 " +
+		"WHERE target_type = 'post' AND resolved IS NULL " +
+		"AND target_id IN (SELECT id FROM dbforum.post WHERE tree @> ARRAY [$1::bigint])"
+
+	// Сообщение удаляется вместе с ответами: путь tree каждого из них
+	// содержит id сообщения. Счётчики участников форума и пользователей
+	// уменьшают триггеры удаления.
+	deletePost = `WITH deleted AS (DELETE FROM dbforum.post WHERE tree @> ARRAY [$1::bigint] RETURNING id)
+				UPDATE dbforum.forum SET posts = posts - (SELECT COUNT(*) FROM deleted) WHERE slug = $2`
+
+	deleteThreadVoteChanges = "DELETE FROM dbforum.vote_changes WHERE thread_id = $1"
+
+	// Голоса за ветку входят в репутацию её автора.
+	deleteThreadVotes = `WITH deleted AS (DELETE FROM dbforum.votes WHERE thread_id = $1 RETURNING voice)
+				UPDATE dbforum.users SET reputation = reputation - (SELECT COALESCE(SUM(voice), 0) FROM deleted)
+				WHERE nickname = $2`
+
+	deleteThreadPosts = `WITH deleted AS (DELETE FROM dbforum.post WHERE thread_id = $1 RETURNING id)
+				UPDATE dbforum.forum SET posts = posts - (SELECT COUNT(*) FROM deleted) WHERE slug = $2`
+
+	deleteThread = `WITH deleted AS (DELETE FROM dbforum.thread WHERE id = $1 RETURNING id)
+				UPDATE dbforum.forum SET threads = threads - (SELECT COUNT(*) FROM deleted) WHERE slug = $2`
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// CreateReport сохраняет жалобу на сообщение (report.Target) или ветку
+// (idOrSlug). Форум и автор берутся из объекта жалобы.
+func (r *Repository) CreateReport(report *models.Report, idOrSlug string, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	var rows *pgx.Rows
+	notFound := customErr.ErrPostNotFound
+	if report.Type == "thread" {
+		notFound = customErr.ErrThreadNotFound
+		if id, parseErr := strconv.ParseUint(idOrSlug, 10, 64); parseErr == nil {
+			rows, err = tx.Query("selectReportedThreadByID", id)
+		} else {
+			rows, err = tx.Query("selectReportedThreadBySlug", idOrSlug)
+		}
+	} else {
+		rows, err = tx.Query("selectReportedPost", report.Target)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if !rows.Next() {
+		rows.Close()
+		_ = tx.Rollback()
+		return notFound
+	}
+	var author string
+	err = rows.Scan(&report.Target, &report.Forum, &author)
+	rows.Close()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	err = tx.QueryRow("insertReport",
+		report.Type,
+		report.Target,
+		report.Forum,
+		author,
+		report.Reporter,
+		report.Reason).Scan(
+		&report.ID,
+		&report.Reporter,
+		&report.Created)
+	if driverErr, ok := err.(pgx.PgError); ok {
+		switch driverErr.Code {
+		case "23503":
+			_ = tx.Rollback()
+			return customErr.ErrUserNotFound
+		case "23505":
+			_ = tx.Rollback()
+			return customErr.ErrReportExists
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetModerationQueue(forumSlug string, limit int) ([]models.ModerationItem, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query("selectReportForumSlug", forumSlug)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if !rows.Next() {
+		rows.Close()
+		_ = tx.Rollback()
+		return nil, customErr.ErrForumNotFound
+	}
+	err = rows.Scan(&forumSlug)
+	rows.Close()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	rows, err = tx.Query("selectModerationQueue", forumSlug, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var queue []models.ModerationItem
+	for rows.Next() {
+		item := models.ModerationItem{}
+		err := rows.Scan(
+			&item.Type,
+			&item.Target,
+			&item.Forum,
+			&item.Author,
+			&item.Reports,
+			&item.Reasons,
+			&item.FirstReported,
+			&item.LastReported)
+		if err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return nil, err
+		}
+		queue = append(queue, item)
+	}
+	rows.Close()
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return queue, nil
+}
+
+// Resolve применяет решение модератора к объекту с открытыми жалобами в
+// форуме forumSlug, закрывает все жалобы на него и записывает действие в
+// журнал.
+func (r *Repository) Resolve(forumSlug string, action models.ModerationAction, actor models.Actor) (models.ModerationResult, error) {
+	result := models.ModerationResult{Action: action.Action}
+	moderator := actor.Nickname
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return result, err
+	}
+	rows, err := tx.Query("selectReportForumSlug", forumSlug)
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+	if !rows.Next() {
+		rows.Close()
+		_ = tx.Rollback()
+		return result, customErr.ErrForumNotFound
+	}
+	err = rows.Scan(&forumSlug)
+	rows.Close()
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+	var author string
+	rows, err = tx.Query("selectOpenReportAuthor", action.Type, action.Target, forumSlug)
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+	if !rows.Next() {
+		rows.Close()
+		_ = tx.Rollback()
+		return result, customErr.ErrReportNotFound
+	}
+	err = rows.Scan(&author)
+	rows.Close()
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+
+	tag, err := tx.Exec("resolveReports", action.Type, action.Target, action.Action, moderator)
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+	result.Resolved = tag.RowsAffected()
+
+	switch action.Action {
+	case "hide":
+		if action.Type == "thread" {
+			_, err = tx.Exec("hideThread", action.Target)
+		} else {
+			_, err = tx.Exec("hidePost", action.Target)
+		}
+	case "approve":
+		if action.Type == "thread" {
+			_, err = tx.Exec("showThread", action.Target)
+		} else {
+			_, err = tx.Exec("showPost", action.Target)
+		}
+	case "delete":
+		if action.Type == "thread" {
+			err = deleteThreadTx(tx, action.Target, forumSlug, author, moderator)
+		} else {
+			err = deletePostTx(tx, action.Target, forumSlug, moderator)
+		}
+	case "ban":
+		ban := &models.Ban{
+			Nickname: author,
+			Forum:    forumSlug,
+			Reason:   action.Reason,
+			BannedBy: moderator,
+			Until:    action.Until,
+		}
+		if err = banRepo.InsertBan(tx, ban); err == nil {
+			result.Ban = ban
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+
+	err = auditRepo.Record(tx, models.AuditEntry{
+		Actor:      moderator,
+		Action:     "moderation." + action.Action,
+		TargetType: action.Type,
+		TargetID:   strconv.FormatUint(action.Target, 10),
+		Forum:      forumSlug,
+		Details:    action.Reason,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return result, err
+	}
+	return result, nil
+}
+
+// Hold ставит задержанную фильтром содержимого запись в очередь модерации
+// форума в транзакции её создания.
+func Hold(tx *pgx.Tx, targetType string, targetID uint64, forumSlug string, author string, reason string) error {
+	_, err := tx.Exec("insertHeldReport", targetType, targetID, forumSlug, author, reason)
+	return err
+}
+
+func deletePostTx(tx *pgx.Tx, postID uint64, forumSlug string, moderator string) error {
+	if _, err := tx.Exec("resolveSubtreeReports", postID, "delete", moderator); err != nil {
+		return err
+	}
+	_, err := tx.Exec("deletePost", postID, forumSlug)
+	return err
+}
+
+func deleteThreadTx(tx *pgx.Tx, threadID uint64, forumSlug string, author string, moderator string) error {
+	if _, err := tx.Exec("resolveThreadPostReports", threadID, "delete", moderator); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("deleteThreadVoteChanges", threadID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("deleteThreadVotes", threadID, author); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("deleteThreadPosts", threadID, forumSlug); err != nil {
+		return err
+	}
+	_, err := tx.Exec("deleteThread", threadID, forumSlug)
+	return err
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectReportedPost", selectReportedPost)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectReportedThreadByID", selectReportedThreadByID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectReportedThreadBySlug", selectReportedThreadBySlug)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertReport", insertReport)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertHeldReport", insertHeldReport)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectReportForumSlug", selectReportForumSlug)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectModerationQueue", selectModerationQueue)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectOpenReportAuthor", selectOpenReportAuthor)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("resolveReports", resolveReports)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("resolveThreadPostReports", resolveThreadPostReports)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("hidePost", hidePost)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("hideThread", hideThread)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("showPost", showPost)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("showThread", showThread)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("resolveSubtreeReports", resolveSubtreeReports)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deletePost", deletePost)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteThreadVoteChanges", deleteThreadVoteChanges)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteThreadVotes", deleteThreadVotes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteThreadPosts", deleteThreadPosts)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteThread", deleteThread)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	reportRepo "DBForum/internal/app/report/repository"
+	"time"
+)
+
+// Объекты жалоб.
+const (
+	TargetPost   = "post"
+	TargetThread = "thread"
+)
+
+// Решения модератора по жалобам.
+const (
+	ActionDismiss = "dismiss"
+	ActionHide    = "hide"
+	ActionDelete  = "delete"
+	ActionBan     = "ban"
+	// Возвращает скрытую или задержанную фильтром запись.
+	ActionApprove = "approve"
+)
+
+type UseCase struct {
+	repo reportRepo.Repository
+}
+
+func NewUseCase(repo reportRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) ReportPost(report models.Report, actor models.Actor) (models.Report, error) {
+	report.Type = TargetPost
+	if err := u.repo.CreateReport(&report, "", actor); err != nil {
+		return models.Report{}, err
+	}
+	return report, nil
+}
+
+func (u *UseCase) ReportThread(report models.Report, idOrSlug string, actor models.Actor) (models.Report, error) {
+	report.Type = TargetThread
+	if err := u.repo.CreateReport(&report, idOrSlug, actor); err != nil {
+		return models.Report{}, err
+	}
+	return report, nil
+}
+
+func (u *UseCase) GetModerationQueue(forumSlug string, limit int) ([]models.ModerationItem, error) {
+	queue, err := u.repo.GetModerationQueue(forumSlug, limit)
+	if err != nil {
+		return nil, err
+	}
+	if queue == nil {
+		return []models.ModerationItem{}, nil
+	}
+	return queue, nil
+}
+
+// Resolve закрывает все открытые жалобы на объект решением action.
+// Бан автора действует в форуме жалобы.
+func (u *UseCase) Resolve(forumSlug string, action models.ModerationAction, actor models.Actor) (models.ModerationResult, error) {
+	if action.Type != TargetPost && action.Type != TargetThread {
+		return models.ModerationResult{}, customErr.ErrUnknownModerationAction
+	}
+	switch action.Action {
+	case ActionDismiss, ActionHide, ActionDelete, ActionApprove:
+	case ActionBan:
+		if action.Until != nil && !action.Until.After(time.Now()) {
+			return models.ModerationResult{}, customErr.ErrInvalidBanPeriod
+		}
+	default:
+		return models.ModerationResult{}, customErr.ErrUnknownModerationAction
+	}
+	return u.repo.Resolve(forumSlug, action, actor)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	revisionUseCase "DBForum/internal/app/revision/usecase"
+	"errors"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type Handlers struct {
+	useCase revisionUseCase.UseCase
+}
+
+func NewHandler(useCase revisionUseCase.UseCase) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+	}
+}
+
+// GetPostRevisions версии сообщения от исходной до текущей.
+func (h *Handlers) GetPostRevisions(ctx *fasthttp.RequestCtx) {
+	id, _ := strconv.ParseUint(ctx.UserValue("id").(string), 10, 64)
+
+	var revisions models.RevisionList
+	revisions, err := h.useCase.GetPostRevisions(id, middleware.Actor(ctx))
+	if h.respondRevisionErr(ctx, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, revisions)
+}
+
+func (h *Handlers) DiffPostRevisions(ctx *fasthttp.RequestCtx) {
+	id, _ := strconv.ParseUint(ctx.UserValue("id").(string), 10, 64)
+	// Номера сравниваемых версий; по умолчанию текущая и предыдущая.
+	from := ctx.QueryArgs().GetUintOrZero("from")
+	to := ctx.QueryArgs().GetUintOrZero("to")
+
+	diff, err := h.useCase.DiffPostRevisions(id, from, to, middleware.Actor(ctx))
+	if h.respondRevisionErr(ctx, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, diff)
+}
+
+// GetThreadRevisions версии заголовка и текста ветки.
+func (h *Handlers) GetThreadRevisions(ctx *fasthttp.RequestCtx) {
+	idOrSlug := ctx.UserValue("slug_or_id").(string)
+
+	var revisions models.RevisionList
+	revisions, err := h.useCase.GetThreadRevisions(idOrSlug, middleware.Actor(ctx))
+	if h.respondRevisionErr(ctx, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, revisions)
+}
+
+func (h *Handlers) DiffThreadRevisions(ctx *fasthttp.RequestCtx) {
+	idOrSlug := ctx.UserValue("slug_or_id").(string)
+	from := ctx.QueryArgs().GetUintOrZero("from")
+	to := ctx.QueryArgs().GetUintOrZero("to")
+
+	diff, err := h.useCase.DiffThreadRevisions(idOrSlug, from, to, middleware.Actor(ctx))
+	if h.respondRevisionErr(ctx, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, diff)
+}
+
+func (h *Handlers) respondRevisionErr(ctx *fasthttp.RequestCtx, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrPostNotFound) {
+		resp := map[string]string{
+			"message": "Can't find post with id: " + ctx.UserValue("id").(string),
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrThreadNotFound) {
+		resp := map[string]string{
+			"message": "Can't find thread by slug or id: " + ctx.UserValue("slug_or_id").(string),
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrRevisionNotFound) {
+		resp := map[string]string{
+			"message": "Can't find revision",
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
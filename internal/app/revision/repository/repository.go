@@ -0,0 +1,165 @@
+package repository
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+	"strconv"
+)
+
+const (
+	// Прежние версии и текущая, которая хранится в самой записи.
+	selectPostRevisions = `SELECT revision, '', message, editor, created, false
+				FROM dbforum.post_revisions WHERE post_id = $1
+				UNION ALL
+				SELECT edit_count + 1, '', message, COALESCE(NULLIF(last_editor, ''), author_nickname),
+					COALESCE(last_edited, created), true
+				FROM dbforum.post WHERE id = $1
+				ORDER BY 1`
+
+	selectThreadRevisions = `SELECT revision, title, message, editor, created, false
+				FROM dbforum.thread_revisions WHERE thread_id = $1
+				UNION ALL
+				SELECT edit_count + 1, title, message, COALESCE(NULLIF(last_editor, ''), author_nickname),
+					COALESCE(last_edited, created), true
+				FROM dbforum.thread WHERE id = $1
+				ORDER BY 1`
+
+	selectRevisionPost = "SELECT author_nickname, forum_slug, hidden FROM dbforum.post WHERE id = $1"
+
+	selectRevisionThreadByID   = "SELECT id, author_nickname, forum_slug, hidden FROM dbforum.thread WHERE id = $1"
+	selectRevisionThreadBySlug = "SELECT id, author_nickname, forum_slug, hidden FROM dbforum.thread WHERE slug = $1"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GetPostRevisions версии сообщения и сведения для проверки права их читать.
+func (r *Repository) GetPostRevisions(id uint64) ([]models.Revision, models.Visibility, error) {
+	var visibility models.Visibility
+	err := r.db.QueryRow("selectRevisionPost", id).Scan(
+		&visibility.Author,
+		&visibility.Forum,
+		&visibility.Hidden)
+	if err == pgx.ErrNoRows {
+		return nil, models.Visibility{}, customErr.ErrPostNotFound
+	}
+	if err != nil {
+		return nil, models.Visibility{}, err
+	}
+	rows, err := r.db.Query("selectPostRevisions", id)
+	if err != nil {
+		return nil, models.Visibility{}, err
+	}
+	revisions, err := scanRevisions(rows)
+	if err != nil {
+		return nil, models.Visibility{}, err
+	}
+	if len(revisions) == 0 {
+		return nil, models.Visibility{}, customErr.ErrPostNotFound
+	}
+	return revisions, visibility, nil
+}
+
+// GetThreadRevisions версии ветки и сведения для проверки права их читать.
+func (r *Repository) GetThreadRevisions(idOrSlug string) ([]models.Revision, models.Visibility, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, models.Visibility{}, err
+	}
+	var row *pgx.Row
+	if id, err := strconv.ParseUint(idOrSlug, 10, 64); err == nil {
+		row = tx.QueryRow("selectRevisionThreadByID", id)
+	} else {
+		row = tx.QueryRow("selectRevisionThreadBySlug", idOrSlug)
+	}
+	var id uint64
+	var visibility models.Visibility
+	err = row.Scan(
+		&id,
+		&visibility.Author,
+		&visibility.Forum,
+		&visibility.Hidden)
+	if err == pgx.ErrNoRows {
+		_ = tx.Rollback()
+		return nil, models.Visibility{}, customErr.ErrThreadNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, models.Visibility{}, err
+	}
+	rows, err := tx.Query("selectThreadRevisions", id)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, models.Visibility{}, err
+	}
+	revisions, err := scanRevisions(rows)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, models.Visibility{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, models.Visibility{}, err
+	}
+	if len(revisions) == 0 {
+		return nil, models.Visibility{}, customErr.ErrThreadNotFound
+	}
+	return revisions, visibility, nil
+}
+
+func scanRevisions(rows *pgx.Rows) ([]models.Revision, error) {
+	defer rows.Close()
+	var revisions []models.Revision
+	for rows.Next() {
+		rev := models.Revision{}
+		err := rows.Scan(
+			&rev.Revision,
+			&rev.Title,
+			&rev.Message,
+			&rev.Editor,
+			&rev.Created,
+			&rev.Current)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectPostRevisions", selectPostRevisions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectThreadRevisions", selectThreadRevisions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRevisionPost", selectRevisionPost)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRevisionThreadByID", selectRevisionThreadByID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRevisionThreadBySlug", selectRevisionThreadBySlug)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	revisionRepo "DBForum/internal/app/revision/repository"
+	roleUseCase "DBForum/internal/app/role/usecase"
+	"strings"
+)
+
+type UseCase struct {
+	repo revisionRepo.Repository
+}
+
+func NewUseCase(repo revisionRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+// GetPostRevisions версии сообщения. Версии скрытого или задержанного
+// сообщения видят только его автор и модераторы форума, остальным оно
+// не найдено.
+func (u *UseCase) GetPostRevisions(id uint64, actor models.Actor) ([]models.Revision, error) {
+	revisions, visibility, err := u.repo.GetPostRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	if !roleUseCase.CanView(actor, visibility) {
+		return nil, customErr.ErrPostNotFound
+	}
+	return revisions, nil
+}
+
+func (u *UseCase) GetThreadRevisions(idOrSlug string, actor models.Actor) ([]models.Revision, error) {
+	revisions, visibility, err := u.repo.GetThreadRevisions(idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	if !roleUseCase.CanView(actor, visibility) {
+		return nil, customErr.ErrThreadNotFound
+	}
+	return revisions, nil
+}
+
+// DiffPostRevisions сравнивает версии from и to сообщения. Нулевой to
+// означает текущую версию, нулевой from - предыдущую перед to.
+func (u *UseCase) DiffPostRevisions(id uint64, from int, to int, actor models.Actor) (models.RevisionDiff, error) {
+	revisions, err := u.GetPostRevisions(id, actor)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	return diffRevisions(revisions, from, to)
+}
+
+func (u *UseCase) DiffThreadRevisions(idOrSlug string, from int, to int, actor models.Actor) (models.RevisionDiff, error) {
+	revisions, err := u.GetThreadRevisions(idOrSlug, actor)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	diff, err := diffRevisions(revisions, from, to)
+	if err != nil {
+		return models.RevisionDiff{}, err
+	}
+	diff.Title = diffLines(revisions[diff.From-1].Title, revisions[diff.To-1].Title)
+	return diff, nil
+}
+
+// diffRevisions рассчитывает на то, что версии пронумерованы подряд с 1.
+func diffRevisions(revisions []models.Revision, from int, to int) (models.RevisionDiff, error) {
+	if to == 0 {
+		to = len(revisions)
+	}
+	if from == 0 {
+		from = to - 1
+		if from == 0 {
+			from = 1
+		}
+	}
+	if from < 1 || from > len(revisions) || to < 1 || to > len(revisions) {
+		return models.RevisionDiff{}, customErr.ErrRevisionNotFound
+	}
+	return models.RevisionDiff{
+		From:    from,
+		To:      to,
+		Message: diffLines(revisions[from-1].Message, revisions[to-1].Message),
+	}, nil
+}
+
+// Наибольший размер таблицы diffLines (около 8 МБ). Если изменённая
+// середина текстов больше, она выводится целиком удалённой и вставленной.
+const maxDiffCells = 1 << 20
+
+// diffLines построчное сравнение по наибольшей общей подпоследовательности.
+// Общие начало и конец текстов в таблицу не входят.
+func diffLines(a string, b string) []models.DiffLine {
+	x := strings.Split(a, "\n")
+	y := strings.Split(b, "\n")
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix &&
+		x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]models.DiffLine, 0, len(x)+len(y))
+	for _, line := range x[:prefix] {
+		diff = append(diff, models.DiffLine{Op: "equal", Text: line})
+	}
+	diff = append(diff, diffMiddle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		diff = append(diff, models.DiffLine{Op: "equal", Text: line})
+	}
+	return diff
+}
+
+func diffMiddle(x []string, y []string) []models.DiffLine {
+	diff := make([]models.DiffLine, 0, len(x)+len(y))
+	if (len(x)+1)*(len(y)+1) > maxDiffCells {
+		for _, line := range x {
+			diff = append(diff, models.DiffLine{Op: "delete", Text: line})
+		}
+		for _, line := range y {
+			diff = append(diff, models.DiffLine{Op: "insert", Text: line})
+		}
+		return diff
+	}
+	// lcs[i][j] - длина общей подпоследовательности x[i:] и y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			diff = append(diff, models.DiffLine{Op: "equal", Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, models.DiffLine{Op: "delete", Text: x[i]})
+			i++
+		default:
+			diff = append(diff, models.DiffLine{Op: "insert", Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		diff = append(diff, models.DiffLine{Op: "delete", Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		diff = append(diff, models.DiffLine{Op: "insert", Text: y[j]})
+	}
+	return diff
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	roleUseCase "DBForum/internal/app/role/usecase"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase roleUseCase.UseCase
+}
+
+func NewHandler(useCase roleUseCase.UseCase) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+	}
+}
+
+func (h *Handlers) Grant(ctx *fasthttp.RequestCtx) {
+	var role models.Role
+	if err := easyjson.Unmarshal(ctx.PostBody(), &role); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	err := h.useCase.GrantRole(role, middleware.Actor(ctx))
+	if h.respondRoleErr(ctx, role, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusCreated, role)
+}
+
+func (h *Handlers) Revoke(ctx *fasthttp.RequestCtx) {
+	var role models.Role
+	if err := easyjson.Unmarshal(ctx.PostBody(), &role); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	err := h.useCase.RevokeRole(role, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrRoleNotFound) {
+		resp := map[string]string{
+			"message": "User " + role.Nickname + " has no role " + role.Role,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if h.respondRoleErr(ctx, role, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, role)
+}
+
+func (h *Handlers) GetUserRoles(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	var roles models.RoleList
+	roles, err := h.useCase.GetUserRoles(nickname)
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, roles)
+}
+
+func (h *Handlers) respondRoleErr(ctx *fasthttp.RequestCtx, role models.Role, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrUnknownRole) {
+		resp := map[string]string{
+			"message": "Unknown role or missing forum for forum_moderator: " + role.Role,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + role.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrForumNotFound) {
+		resp := map[string]string{
+			"message": "Can't find forum by slug: " + role.Forum,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
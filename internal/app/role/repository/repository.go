@@ -0,0 +1,133 @@
+package repository
+
+import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+)
+
+const (
+	insertRole = `INSERT INTO dbforum.roles(nickname, role, forum_slug, granted_by)
+				VALUES ((SELECT nickname FROM dbforum.users WHERE nickname = $1), $2, $3, $4)
+				ON CONFLICT DO NOTHING`
+
+	deleteRole = "DELETE FROM dbforum.roles WHERE nickname = $1 AND role = $2 AND forum_slug = $3"
+
+	selectRolesByNickname = "SELECT nickname, role, forum_slug FROM dbforum.roles WHERE nickname = $1 ORDER BY role, forum_slug"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GrantRole выдаёт роль от имени actor; он же записывается в granted_by.
+func (r *Repository) GrantRole(role models.Role, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	if role.Forum != "" {
+		rows, err := tx.Query("checkRoleForum", role.Forum)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if !rows.Next() {
+			rows.Close()
+			_ = tx.Rollback()
+			return customErr.ErrForumNotFound
+		}
+		rows.Close()
+	}
+	_, err = tx.Exec("insertRole", role.Nickname, role.Role, role.Forum, actor.Nickname)
+	if driverErr, ok := err.(pgx.PgError); ok {
+		// Подзапрос вернул NULL: пользователя не существует.
+		if driverErr.Code == "23502" {
+			_ = tx.Rollback()
+			return customErr.ErrUserNotFound
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) RevokeRole(role models.Role, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec("deleteRole", role.Nickname, role.Role, role.Forum)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback()
+		return customErr.ErrRoleNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetUserRoles(nickname string) ([]models.Role, error) {
+	rows, err := r.db.Query("selectRolesByNickname", nickname)
+	if err != nil {
+		return nil, err
+	}
+	var roles []models.Role
+	for rows.Next() {
+		role := models.Role{}
+		err := rows.Scan(
+			&role.Nickname,
+			&role.Role,
+			&role.Forum)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	rows.Close()
+	return roles, nil
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("insertRole", insertRole)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteRole", deleteRole)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRolesByNickname", selectRolesByNickname)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("checkRoleForum", "SELECT 1 FROM dbforum.forum WHERE slug = $1 LIMIT 1")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
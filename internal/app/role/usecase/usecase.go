@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	roleRepo "DBForum/internal/app/role/repository"
+	"strings"
+	"time"
+)
+
+// Роли пользователей. Пользователь без ролей считается обычным.
+const (
+	RoleAdmin          = "admin"
+	RoleModerator      = "moderator"
+	RoleForumModerator = "forum_moderator"
+	RoleReadOnly       = "readonly"
+)
+
+type Permission int
+
+const (
+	// PermWrite создание и изменение контента от своего имени.
+	PermWrite Permission = iota
+	// PermModerate модерация форума; для модератора форума только своего.
+	PermModerate
+	// PermAdmin управление ролями и сервисные операции.
+	PermAdmin
+)
+
+type UseCase struct {
+	repo roleRepo.Repository
+}
+
+func NewUseCase(repo roleRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) GrantRole(role models.Role, actor models.Actor) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+	return u.repo.GrantRole(role, actor)
+}
+
+func (u *UseCase) RevokeRole(role models.Role, actor models.Actor) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+	return u.repo.RevokeRole(role, actor)
+}
+
+func (u *UseCase) GetUserRoles(nickname string) ([]models.Role, error) {
+	roles, err := u.repo.GetUserRoles(nickname)
+	if err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		return []models.Role{}, nil
+	}
+	return roles, nil
+}
+
+// Allowed проверяет, дают ли роли разрешение perm в форуме forumSlug.
+// Для глобальных операций forumSlug пустой.
+func Allowed(roles []models.Role, perm Permission, forumSlug string) bool {
+	var admin, moderator, readOnly bool
+	for _, role := range roles {
+		switch role.Role {
+		case RoleAdmin:
+			admin = true
+		case RoleModerator:
+			moderator = true
+		case RoleForumModerator:
+			if forumSlug != "" && strings.EqualFold(role.Forum, forumSlug) {
+				moderator = true
+			}
+		case RoleReadOnly:
+			readOnly = true
+		}
+	}
+	switch perm {
+	case PermWrite:
+		return admin || !readOnly
+	case PermModerate:
+		return admin || moderator
+	case PermAdmin:
+		return admin
+	}
+	return false
+}
+
+// CanEdit проверяет право actor править запись: автор может править её в
+// течение window после создания, модератор форума - в любое время.
+// Анонимно можно править только записи авторов без пароля.
+func CanEdit(actor models.Actor, info models.EditInfo, window time.Duration) error {
+	if Allowed(actor.Roles, PermModerate, info.Forum) {
+		return nil
+	}
+	if actor.Nickname != "" && !strings.EqualFold(actor.Nickname, info.Author) {
+		return customErr.ErrForbidden
+	}
+	if actor.Nickname == "" && info.Protected {
+		return customErr.ErrForbidden
+	}
+	if window > 0 && time.Since(info.Created) > window {
+		return customErr.ErrEditWindowExpired
+	}
+	return nil
+}
+
+// CanView проверяет право actor читать запись: скрытую или задержанную
+// видят только её автор и модераторы форума.
+func CanView(actor models.Actor, v models.Visibility) bool {
+	if !v.Hidden {
+		return true
+	}
+	if actor.Nickname != "" && strings.EqualFold(actor.Nickname, v.Author) {
+		return true
+	}
+	return Allowed(actor.Roles, PermModerate, v.Forum)
+}
+
+func validateRole(role models.Role) error {
+	switch role.Role {
+	case RoleAdmin, RoleModerator, RoleReadOnly:
+		if role.Forum != "" {
+			return customErr.ErrUnknownRole
+		}
+	case RoleForumModerator:
+		if role.Forum == "" {
+			return customErr.ErrUnknownRole
+		}
+	default:
+		return customErr.ErrUnknownRole
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/models"
+	searchUseCase "DBForum/internal/app/search/usecase"
+	"errors"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+	"time"
+)
+
+type Handlers struct {
+	useCase searchUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase searchUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+func (h *Handlers) Search(ctx *fasthttp.RequestCtx) {
+	// since и until здесь - диапазон дат, а не начало страницы, их разбор ниже.
+	paging, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceNone})
+	if !ok {
+		return
+	}
+	args := ctx.QueryArgs()
+	query := models.SearchQuery{
+		// Поисковый запрос в синтаксисе websearch_to_tsquery.
+		Query: string(args.Peek("q")),
+		// Тип результатов: thread, post. По умолчанию ищутся оба.
+		Type:   string(args.Peek("type")),
+		Forum:  string(args.Peek("forum")),
+		Author: string(args.Peek("author")),
+		Limit:  paging.Limit,
+	}
+	if query.Query == "" {
+		resp := map[string]string{
+			"message": "Search query is empty",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if query.Type != "" && query.Type != "thread" && query.Type != "post" {
+		resp := map[string]string{
+			"message": "Unknown search type: " + query.Type,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	// Диапазон дат создания [since, until) в формате RFC 3339.
+	for _, param := range []string{"since", "until"} {
+		value := string(args.Peek(param))
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			resp := map[string]string{
+				"message": "Can't parse " + param + " date: " + value,
+			}
+			httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+			return
+		}
+		if param == "since" {
+			query.Since = &date
+		} else {
+			query.Until = &date
+		}
+	}
+	// Значение next из предыдущей страницы.
+	cursor := string(args.Peek("cursor"))
+
+	page, err := h.useCase.Search(query, cursor)
+	if errors.Is(err, customErr.ErrInvalidCursor) {
+		resp := map[string]string{
+			"message": "Invalid cursor: " + cursor,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, page)
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+)
+
+const (
+	// Ветки и сообщения ранжируются вместе; страницы листаются по ключу
+	// (rank, type, id) последнего результата.
+	searchContent = `SELECT r.kind, r.id, r.thread_id, r.forum_slug, r.author_nickname, r.title,
+					ts_headline(dbforum.search_language(), r.body, websearch_to_tsquery(dbforum.search_language(), $1),
+						'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=25'),
+					r.rank, r.created
+				FROM (
+					SELECT 'thread' AS kind, t.id, t.id AS thread_id, t.forum_slug, t.author_nickname, t.title,
+						t.message AS body, ts_rank(t.search, q.query) AS rank, t.created
+					FROM dbforum.thread AS t, websearch_to_tsquery(dbforum.search_language(), $1) AS q(query)
+					WHERE $2::text IN ('', 'thread')
+						AND t.search @@ q.query
+						AND NOT t.hidden
+						AND ($3::text = '' OR t.forum_slug = $3::citext)
+						AND ($4::text = '' OR t.author_nickname = $4::citext)
+						AND ($5::timestamptz IS NULL OR t.created >= $5::timestamptz)
+						AND ($6::timestamptz IS NULL OR t.created < $6::timestamptz)
+					UNION ALL
+					SELECT 'post', p.id, p.thread_id, p.forum_slug, p.author_nickname, '',
+						p.message, ts_rank(p.search, q.query), p.created
+					FROM dbforum.post AS p, websearch_to_tsquery(dbforum.search_language(), $1) AS q(query)
+					WHERE $2::text IN ('', 'post')
+						AND p.search @@ q.query
+						AND NOT p.hidden
+						AND ($3::text = '' OR p.forum_slug = $3::citext)
+						AND ($4::text = '' OR p.author_nickname = $4::citext)
+						AND ($5::timestamptz IS NULL OR p.created >= $5::timestamptz)
+						AND ($6::timestamptz IS NULL OR p.created < $6::timestamptz)
+				) AS r
+				WHERE $7::real IS NULL OR (r.rank, r.kind, r.id) < ($7::real, $8::text, $9::bigint)
+				ORDER BY r.rank DESC, r.kind DESC, r.id DESC
+				LIMIT $10`
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) Search(query models.SearchQuery) ([]models.SearchResult, error) {
+	var since, until, afterRank, afterType, afterID interface{}
+	if query.Since != nil {
+		since = *query.Since
+	}
+	if query.Until != nil {
+		until = *query.Until
+	}
+	if query.After != nil {
+		afterRank = query.After.Rank
+		afterType = query.After.Type
+		afterID = query.After.ID
+	}
+
+	rows, err := r.db.Query("searchContent",
+		query.Query,
+		query.Type,
+		query.Forum,
+		query.Author,
+		since,
+		until,
+		afterRank,
+		afterType,
+		afterID,
+		query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	var results []models.SearchResult
+	for rows.Next() {
+		res := models.SearchResult{}
+		err := rows.Scan(
+			&res.Type,
+			&res.ID,
+			&res.Thread,
+			&res.Forum,
+			&res.Author,
+			&res.Title,
+			&res.Snippet,
+			&res.Rank,
+			&res.Created)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("searchContent", searchContent)
+	if err != nil {
+		return err
+	}
+	return nil
+}
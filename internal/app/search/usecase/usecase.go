@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	searchRepo "DBForum/internal/app/search/repository"
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+type UseCase struct {
+	repo searchRepo.Repository
+}
+
+func NewUseCase(repo searchRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) Search(query models.SearchQuery, cursor string) (models.SearchPage, error) {
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return models.SearchPage{}, err
+		}
+		query.After = after
+	}
+	results, err := u.repo.Search(query)
+	if err != nil {
+		return models.SearchPage{}, err
+	}
+	page := models.SearchPage{Results: results}
+	if results == nil {
+		page.Results = []models.SearchResult{}
+	}
+	if len(results) == query.Limit {
+		page.Next = encodeCursor(results[len(results)-1])
+	}
+	return page, nil
+}
+
+func encodeCursor(last models.SearchResult) string {
+	key := strconv.FormatFloat(float64(last.Rank), 'g', -1, 32) + ":" +
+		last.Type + ":" +
+		strconv.FormatUint(last.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCursor(cursor string) (*models.SearchResult, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, customErr.ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, customErr.ErrInvalidCursor
+	}
+	rank, err := strconv.ParseFloat(parts[0], 32)
+	if err != nil {
+		return nil, customErr.ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, customErr.ErrInvalidCursor
+	}
+	if parts[1] != "thread" && parts[1] != "post" {
+		return nil, customErr.ErrInvalidCursor
+	}
+	return &models.SearchResult{
+		Rank: float32(rank),
+		Type: parts[1],
+		ID:   id,
+	}, nil
+}
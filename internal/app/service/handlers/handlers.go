@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
 	serviceUseCase "DBForum/internal/app/service/usecase"
 	"github.com/valyala/fasthttp"
 	"log"
@@ -19,7 +20,7 @@ func NewHandler(useCase serviceUseCase.UseCase) *Handlers {
 }
 
 func (h *Handlers) ClearDB(ctx *fasthttp.RequestCtx) {
-	err := h.useCase.ClearDB()
+	err := h.useCase.ClearDB(middleware.Actor(ctx))
 	if err != nil {
 		httputils.Respond(ctx, http.StatusInternalServerError, nil)
 		log.Println(err)
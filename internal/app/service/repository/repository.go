@@ -1,6 +1,7 @@
 package repository
 
 import (
+	auditRepo "DBForum/internal/app/audit/repository"
 	"DBForum/internal/app/models"
 	"github.com/jackc/pgx"
 )
@@ -15,8 +16,10 @@ func NewRepo(db *pgx.ConnPool) *Repository {
 	}
 }
 
-func (r *Repository) ClearDB() error {
-	tx, err := r.db.Begin()
+// ClearDB очищает все таблицы, включая журнал аудита, и оставляет в журнале
+// единственную запись об очистке.
+func (r *Repository) ClearDB(actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return err
 	}
@@ -24,10 +27,25 @@ func (r *Repository) ClearDB() error {
 	_, err = tx.Exec("truncPost")
 	_, err = tx.Exec("truncForumUsers")
 	_, err = tx.Exec("truncThread")
+	_, err = tx.Exec("truncVoteChanges")
 	_, err = tx.Exec("truncVotes")
 	_, err = tx.Exec("truncForum")
+	_, err = tx.Exec("truncAuditLog")
+	_, err = tx.Exec("truncReports")
+	_, err = tx.Exec("truncBans")
+	_, err = tx.Exec("truncRoles")
+	_, err = tx.Exec("truncSessions")
 	_, err = tx.Exec("truncUsers")
 
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	err = auditRepo.Record(tx, models.AuditEntry{
+		Actor:      actor.Nickname,
+		Action:     "service.clear",
+		TargetType: "service",
+	})
 	if err != nil {
 		_ = tx.Rollback()
 		return err
@@ -74,6 +92,11 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
+	_, err = r.db.Prepare("truncVoteChanges", `TRUNCATE dbforum.vote_changes CASCADE`)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Prepare("truncVotes", `TRUNCATE dbforum.votes CASCADE`)
 	if err != nil {
 		return err
@@ -84,6 +107,31 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
+	_, err = r.db.Prepare("truncAuditLog", `TRUNCATE dbforum.audit_log CASCADE`)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("truncReports", `TRUNCATE dbforum.reports CASCADE`)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("truncBans", `TRUNCATE dbforum.bans CASCADE`)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("truncRoles", `TRUNCATE dbforum.roles CASCADE`)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("truncSessions", `TRUNCATE dbforum.sessions CASCADE`)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Prepare("truncUsers", `TRUNCATE dbforum.users CASCADE`)
 	if err != nil {
 		return err
@@ -15,8 +15,8 @@ func NewUseCase(repo serviceRepo.Repository) *UseCase {
 	}
 }
 
-func (u *UseCase) ClearDB() error {
-	err := u.repo.ClearDB()
+func (u *UseCase) ClearDB(actor models.Actor) error {
+	err := u.repo.ClearDB(actor)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
+	"DBForum/internal/app/models"
+	subscriptionUseCase "DBForum/internal/app/subscription/usecase"
+	"errors"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"log"
+	"net/http"
+)
+
+type Handlers struct {
+	useCase subscriptionUseCase.UseCase
+	pager   httputils.Pager
+}
+
+func NewHandler(useCase subscriptionUseCase.UseCase, pager httputils.Pager) *Handlers {
+	return &Handlers{
+		useCase: useCase,
+		pager:   pager,
+	}
+}
+
+func (h *Handlers) SubscribeThread(ctx *fasthttp.RequestCtx) {
+	sub, ok := h.parseSubscription(ctx)
+	if !ok {
+		return
+	}
+	idOrSlug := ctx.UserValue("slug_or_id").(string)
+
+	created, err := h.useCase.SubscribeThread(sub, idOrSlug)
+	if h.respondSubscriptionErr(ctx, sub, idOrSlug, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, created)
+}
+
+func (h *Handlers) UnsubscribeThread(ctx *fasthttp.RequestCtx) {
+	sub, ok := h.parseSubscription(ctx)
+	if !ok {
+		return
+	}
+	idOrSlug := ctx.UserValue("slug_or_id").(string)
+
+	err := h.useCase.UnsubscribeThread(sub, idOrSlug)
+	if h.respondSubscriptionErr(ctx, sub, idOrSlug, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
+
+func (h *Handlers) SubscribeForum(ctx *fasthttp.RequestCtx) {
+	sub, ok := h.parseSubscription(ctx)
+	if !ok {
+		return
+	}
+	sub.Forum = ctx.UserValue("slug").(string)
+
+	created, err := h.useCase.SubscribeForum(sub)
+	if h.respondSubscriptionErr(ctx, sub, sub.Forum, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, created)
+}
+
+func (h *Handlers) UnsubscribeForum(ctx *fasthttp.RequestCtx) {
+	sub, ok := h.parseSubscription(ctx)
+	if !ok {
+		return
+	}
+	sub.Forum = ctx.UserValue("slug").(string)
+
+	err := h.useCase.UnsubscribeForum(sub)
+	if h.respondSubscriptionErr(ctx, sub, sub.Forum, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, nil)
+}
+
+func (h *Handlers) GetSubscriptions(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	var subs models.SubscriptionList
+	subs, err := h.useCase.GetSubscriptions(nickname, middleware.Actor(ctx))
+	if h.respondSubscriptionErr(ctx, models.Subscription{Nickname: nickname}, "", err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, subs)
+}
+
+// GetWatched лента подписок с прошлого просмотра. Просмотр сдвигает её
+// начало на последние показанные записи.
+func (h *Handlers) GetWatched(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	// limit - максимальное количество сообщений и веток.
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceNone})
+	if !ok {
+		return
+	}
+
+	feed, err := h.useCase.GetWatched(nickname, page.Limit, middleware.Actor(ctx))
+	if h.respondSubscriptionErr(ctx, models.Subscription{Nickname: nickname}, "", err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, feed)
+}
+
+// parseSubscription читает необязательное тело {"nickname": ...}; подписчик -
+// всегда вызывающий, другой никнейм в теле отклоняется.
+func (h *Handlers) parseSubscription(ctx *fasthttp.RequestCtx) (models.Subscription, bool) {
+	var sub models.Subscription
+	if len(ctx.PostBody()) > 0 {
+		if err := easyjson.Unmarshal(ctx.PostBody(), &sub); err != nil {
+			httputils.Respond(ctx, http.StatusInternalServerError, nil)
+			log.Println(err)
+			return sub, false
+		}
+	}
+	nickname, ok := middleware.BindCaller(ctx, sub.Nickname)
+	if !ok {
+		middleware.RespondAuthorMismatch(ctx, sub.Nickname)
+		return sub, false
+	}
+	sub.Nickname = nickname
+	return sub, true
+}
+
+func (h *Handlers) respondSubscriptionErr(ctx *fasthttp.RequestCtx, sub models.Subscription, target string, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't read subscriptions of another user: " + sub.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + sub.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrThreadNotFound) {
+		resp := map[string]string{
+			"message": "Can't find thread by slug or id: " + target,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrForumNotFound) {
+		resp := map[string]string{
+			"message": "Can't find forum by slug: " + target,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrSubscriptionNotFound) {
+		resp := map[string]string{
+			"message": sub.Nickname + " is not subscribed to " + target,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
@@ -0,0 +1,381 @@
+package repository
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	"github.com/jackc/pgx"
+	"strconv"
+	"time"
+)
+
+const (
+	selectSubscribedThreadByID = "SELECT id, forum_slug FROM dbforum.thread WHERE id = $1"
+
+	selectSubscribedThreadBySlug = "SELECT id, forum_slug FROM dbforum.thread WHERE slug = $1"
+
+	selectSubscribedForum = "SELECT slug FROM dbforum.forum WHERE slug = $1"
+
+	selectSubscriber = "SELECT nickname FROM dbforum.users WHERE nickname = $1"
+
+	insertThreadSubscription = `INSERT INTO dbforum.thread_subscriptions(nickname, thread_id) VALUES ($1, $2)
+				ON CONFLICT (nickname, thread_id) DO UPDATE SET nickname = EXCLUDED.nickname
+				RETURNING created`
+
+	insertForumSubscription = `INSERT INTO dbforum.forum_subscriptions(nickname, forum_slug) VALUES ($1, $2)
+				ON CONFLICT (nickname, forum_slug) DO UPDATE SET nickname = EXCLUDED.nickname
+				RETURNING created`
+
+	// Лента начинается с первой подписки, а не с начала форума.
+	// Лента идёт по порядку публикации: одобренная задержанная запись
+	// попадает в неё в момент одобрения.
+	insertWatchedVisit = `INSERT INTO dbforum.watched_visits(nickname, last_post, last_thread)
+				VALUES ($1, (SELECT COALESCE(MAX(published), 0) FROM dbforum.post),
+				        (SELECT COALESCE(MAX(published), 0) FROM dbforum.thread))
+				ON CONFLICT (nickname) DO NOTHING`
+
+	deleteThreadSubscription = "DELETE FROM dbforum.thread_subscriptions WHERE nickname = $1 AND thread_id = $2"
+
+	deleteForumSubscription = "DELETE FROM dbforum.forum_subscriptions WHERE nickname = $1 AND forum_slug = $2"
+
+	selectSubscriptions = `SELECT nickname, 'thread', thread_id, '', created FROM dbforum.thread_subscriptions WHERE nickname = $1
+				UNION ALL
+				SELECT nickname, 'forum', 0, forum_slug, created FROM dbforum.forum_subscriptions WHERE nickname = $1
+				ORDER BY created`
+
+	selectWatchedVisit = "SELECT last_post, last_thread, visited FROM dbforum.watched_visits WHERE nickname = $1"
+
+	selectWatchedPosts = "SELECT published, id, author_nickname, forum_slug, thread_id, message, parent, is_edited, created, edit_count, last_edited " +
+		"FROM dbforum.post " +
+		"WHERE published > $2 AND NOT hidden AND author_nickname <> $1 " +
+		"AND thread_id IN (SELECT thread_id FROM dbforum.thread_subscriptions WHERE nickname = $1) " +
+		"ORDER BY published LIMIT $3"
+
+	selectWatchedThreads = "SELECT published, id, forum_slug, author_nickname, title, message, votes, COALESCE(slug, ''), created, edit_count, last_edited " +
+		"FROM dbforum.thread " +
+		"WHERE published > $2 AND NOT hidden AND author_nickname <> $1 " +
+		"AND forum_slug IN (SELECT forum_slug FROM dbforum.forum_subscriptions WHERE nickname = $1) " +
+		"ORDER BY published LIMIT $3"
+
+	updateWatchedVisit = "UPDATE dbforum.watched_visits " +
+		"SET last_post = GREATEST(last_post, $2), last_thread = GREATEST(last_thread, $3), visited = now() " +
+		"WHERE nickname = $1"
+)
+
+type Repository struct {
+	db *pgx.ConnPool
+}
+
+func NewRepo(db *pgx.ConnPool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// SubscribeThread подписывает на ветку idOrSlug. Повторная подписка не
+// считается ошибкой и возвращает прежнюю.
+func (r *Repository) SubscribeThread(sub *models.Subscription, idOrSlug string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = subscriberTx(tx, sub); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = threadTx(tx, sub, idOrSlug); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = tx.QueryRow("insertThreadSubscription", sub.Nickname, sub.Thread).Scan(&sub.Created); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec("insertWatchedVisit", sub.Nickname); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) SubscribeForum(sub *models.Subscription) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = subscriberTx(tx, sub); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = tx.QueryRow("selectSubscribedForum", sub.Forum).Scan(&sub.Forum); err != nil {
+		_ = tx.Rollback()
+		if err == pgx.ErrNoRows {
+			return customErr.ErrForumNotFound
+		}
+		return err
+	}
+	if err = tx.QueryRow("insertForumSubscription", sub.Nickname, sub.Forum).Scan(&sub.Created); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec("insertWatchedVisit", sub.Nickname); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) UnsubscribeThread(sub *models.Subscription, idOrSlug string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = subscriberTx(tx, sub); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = threadTx(tx, sub, idOrSlug); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	tag, err := tx.Exec("deleteThreadSubscription", sub.Nickname, sub.Thread)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback()
+		return customErr.ErrSubscriptionNotFound
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) UnsubscribeForum(sub *models.Subscription) error {
+	tag, err := r.db.Exec("deleteForumSubscription", sub.Nickname, sub.Forum)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return customErr.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *Repository) GetSubscriptions(nickname string) ([]models.Subscription, error) {
+	var user string
+	err := r.db.QueryRow("selectSubscriber", nickname).Scan(&user)
+	if err == pgx.ErrNoRows {
+		return nil, customErr.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query("selectSubscriptions", user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var subs []models.Subscription
+	for rows.Next() {
+		s := models.Subscription{}
+		if err := rows.Scan(&s.Nickname, &s.Type, &s.Thread, &s.Forum, &s.Created); err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// GetWatched возвращает до limit новых сообщений и веток с прошлого
+// просмотра и запоминает последние показанные. Не показанное из-за limit
+// попадёт в следующий просмотр.
+func (r *Repository) GetWatched(nickname string, limit int) (models.WatchedFeed, error) {
+	feed := models.WatchedFeed{Posts: []models.Post{}, Threads: []models.Thread{}}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return feed, err
+	}
+	var user string
+	err = tx.QueryRow("selectSubscriber", nickname).Scan(&user)
+	if err == pgx.ErrNoRows {
+		_ = tx.Rollback()
+		return feed, customErr.ErrUserNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return feed, err
+	}
+	var lastPost, lastThread int64
+	err = tx.QueryRow("selectWatchedVisit", user).Scan(&lastPost, &lastThread, &feed.Since)
+	if err == pgx.ErrNoRows {
+		// Подписок ещё не было.
+		_ = tx.Rollback()
+		feed.Since = time.Now()
+		return feed, nil
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return feed, err
+	}
+
+	rows, err := tx.Query("selectWatchedPosts", user, lastPost, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return feed, err
+	}
+	for rows.Next() {
+		p := models.Post{}
+		err := rows.Scan(
+			&lastPost,
+			&p.ID,
+			&p.Author,
+			&p.Forum,
+			&p.Thread,
+			&p.Message,
+			&p.Parent,
+			&p.IsEdited,
+			&p.Created,
+			&p.EditCount,
+			&p.LastEdited)
+		if err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return feed, err
+		}
+		feed.Posts = append(feed.Posts, p)
+	}
+	rows.Close()
+
+	rows, err = tx.Query("selectWatchedThreads", user, lastThread, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return feed, err
+	}
+	for rows.Next() {
+		t := models.Thread{}
+		err := rows.Scan(
+			&lastThread,
+			&t.ID,
+			&t.Forum,
+			&t.Author,
+			&t.Title,
+			&t.Message,
+			&t.Votes,
+			&t.Slug,
+			&t.Created,
+			&t.EditCount,
+			&t.LastEdited)
+		if err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return feed, err
+		}
+		feed.Threads = append(feed.Threads, t)
+	}
+	rows.Close()
+
+	if _, err = tx.Exec("updateWatchedVisit", user, lastPost, lastThread); err != nil {
+		_ = tx.Rollback()
+		return feed, err
+	}
+	if err = tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return feed, err
+	}
+	return feed, nil
+}
+
+func subscriberTx(tx *pgx.Tx, sub *models.Subscription) error {
+	err := tx.QueryRow("selectSubscriber", sub.Nickname).Scan(&sub.Nickname)
+	if err == pgx.ErrNoRows {
+		return customErr.ErrUserNotFound
+	}
+	return err
+}
+
+func threadTx(tx *pgx.Tx, sub *models.Subscription, idOrSlug string) error {
+	var row *pgx.Row
+	if id, err := strconv.ParseUint(idOrSlug, 10, 64); err == nil {
+		row = tx.QueryRow("selectSubscribedThreadByID", id)
+	} else {
+		row = tx.QueryRow("selectSubscribedThreadBySlug", idOrSlug)
+	}
+	err := row.Scan(&sub.Thread, &sub.Forum)
+	if err == pgx.ErrNoRows {
+		return customErr.ErrThreadNotFound
+	}
+	return err
+}
+
+func (r *Repository) Prepare() error {
+	_, err := r.db.Prepare("selectSubscribedThreadByID", selectSubscribedThreadByID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectSubscribedThreadBySlug", selectSubscribedThreadBySlug)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectSubscribedForum", selectSubscribedForum)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectSubscriber", selectSubscriber)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertThreadSubscription", insertThreadSubscription)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertForumSubscription", insertForumSubscription)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertWatchedVisit", insertWatchedVisit)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteThreadSubscription", deleteThreadSubscription)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteForumSubscription", deleteForumSubscription)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectSubscriptions", selectSubscriptions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectWatchedVisit", selectWatchedVisit)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectWatchedPosts", selectWatchedPosts)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectWatchedThreads", selectWatchedThreads)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("updateWatchedVisit", updateWatchedVisit)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	customErr "DBForum/internal/app/errors"
+	"DBForum/internal/app/models"
+	subscriptionRepo "DBForum/internal/app/subscription/repository"
+	"strings"
+)
+
+// Объекты подписок.
+const (
+	TargetThread = "thread"
+	TargetForum  = "forum"
+)
+
+type UseCase struct {
+	repo subscriptionRepo.Repository
+}
+
+func NewUseCase(repo subscriptionRepo.Repository) *UseCase {
+	return &UseCase{
+		repo: repo,
+	}
+}
+
+func (u *UseCase) SubscribeThread(sub models.Subscription, idOrSlug string) (models.Subscription, error) {
+	sub.Type = TargetThread
+	if err := u.repo.SubscribeThread(&sub, idOrSlug); err != nil {
+		return models.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (u *UseCase) SubscribeForum(sub models.Subscription) (models.Subscription, error) {
+	sub.Type = TargetForum
+	if err := u.repo.SubscribeForum(&sub); err != nil {
+		return models.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (u *UseCase) UnsubscribeThread(sub models.Subscription, idOrSlug string) error {
+	return u.repo.UnsubscribeThread(&sub, idOrSlug)
+}
+
+func (u *UseCase) UnsubscribeForum(sub models.Subscription) error {
+	return u.repo.UnsubscribeForum(&sub)
+}
+
+// GetSubscriptions подписки видны только самому пользователю.
+func (u *UseCase) GetSubscriptions(nickname string, actor models.Actor) ([]models.Subscription, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return nil, customErr.ErrForbidden
+	}
+	subs, err := u.repo.GetSubscriptions(nickname)
+	if err != nil {
+		return nil, err
+	}
+	if subs == nil {
+		return []models.Subscription{}, nil
+	}
+	return subs, nil
+}
+
+func (u *UseCase) GetWatched(nickname string, limit int, actor models.Actor) (models.WatchedFeed, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) {
+		return models.WatchedFeed{}, customErr.ErrForbidden
+	}
+	return u.repo.GetWatched(nickname, limit)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
 	"DBForum/internal/app/models"
 	threadUseCase "DBForum/internal/app/thread/usecase"
 	"github.com/mailru/easyjson"
@@ -15,11 +16,13 @@ import (
 
 type Handlers struct {
 	useCase threadUseCase.UseCase
+	pager   httputils.Pager
 }
 
-func NewHandler(useCase threadUseCase.UseCase) *Handlers {
+func NewHandler(useCase threadUseCase.UseCase, pager httputils.Pager) *Handlers {
 	return &Handlers{
 		useCase: useCase,
+		pager:   pager,
 	}
 }
 
@@ -31,8 +34,16 @@ func (h *Handlers) CreatePost(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	for i := range posts {
+		var ok bool
+		if posts[i].Author, ok = middleware.BindAuthor(ctx, posts[i].Author); !ok {
+			middleware.RespondAuthorMismatch(ctx, posts[i].Author)
+			return
+		}
+	}
+
 	idOrSlug := ctx.UserValue("slug_or_id").(string)
-	posts, err := h.useCase.CreatePosts(idOrSlug, posts)
+	posts, err := h.useCase.CreatePosts(idOrSlug, posts, middleware.Actor(ctx))
 	if errors.Is(err, customErr.ErrThreadNotFound) {
 		var message string
 		if _, err := strconv.ParseUint(idOrSlug, 10, 64); err != nil {
@@ -60,6 +71,20 @@ func (h *Handlers) CreatePost(ctx *fasthttp.RequestCtx) {
 		httputils.RespondErr(ctx, http.StatusConflict, resp)
 		return
 	}
+	if errors.Is(err, customErr.ErrBlocked) {
+		resp := map[string]string{
+			"code":    "blocked",
+			"message": "Parent post author has blocked the author",
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if httputils.RespondBanned(ctx, err) {
+		return
+	}
+	if httputils.RespondRejected(ctx, err) {
+		return
+	}
 	if err != nil {
 		httputils.Respond(ctx, http.StatusInternalServerError, nil)
 		log.Println(err)
@@ -70,7 +95,7 @@ func (h *Handlers) CreatePost(ctx *fasthttp.RequestCtx) {
 
 func (h *Handlers) ThreadInfo(ctx *fasthttp.RequestCtx) {
 	idOrSlug := ctx.UserValue("slug_or_id").(string)
-	thread, err := h.useCase.ThreadInfo(idOrSlug)
+	thread, err := h.useCase.ThreadInfo(idOrSlug, middleware.Actor(ctx))
 	if errors.Is(err, customErr.ErrForumNotFound) {
 		resp := map[string]string{
 			"message": "Can't find thread by slug or id: " + idOrSlug,
@@ -95,7 +120,7 @@ func (h *Handlers) ChangeThread(ctx *fasthttp.RequestCtx) {
 	}
 
 	idOrSlug := ctx.UserValue("slug_or_id").(string)
-	thread, err := h.useCase.ChangeThread(idOrSlug, thread)
+	thread, err := h.useCase.ChangeThread(idOrSlug, thread, middleware.Actor(ctx))
 
 	if errors.Is(err, customErr.ErrThreadNotFound) {
 		resp := map[string]string{
@@ -104,6 +129,21 @@ func (h *Handlers) ChangeThread(ctx *fasthttp.RequestCtx) {
 		httputils.RespondErr(ctx, http.StatusNotFound, resp)
 		return
 	}
+	if errors.Is(err, customErr.ErrEditWindowExpired) {
+		resp := map[string]string{
+			"code":    "edit_window_expired",
+			"message": "Edit window for thread has passed: " + idOrSlug,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't edit thread of another user: " + idOrSlug,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
 	if err != nil {
 		httputils.Respond(ctx, http.StatusInternalServerError, nil)
 		log.Println(err)
@@ -115,16 +155,9 @@ func (h *Handlers) ChangeThread(ctx *fasthttp.RequestCtx) {
 func (h *Handlers) GetPosts(ctx *fasthttp.RequestCtx) {
 	idOrSlug := ctx.UserValue("slug_or_id").(string)
 
-	// максимальное количество возвращаемых записей
-	limit := int64(ctx.QueryArgs().GetUintOrZero("limit"))
-	if limit == 0 {
-		limit = 100
-	}
-
-	// Дата создания ветви обсуждения, с которой будут выводиться записи
-	// (ветвь обсуждения с указанной датой попадает в результат выборки).
-	since := int64(ctx.QueryArgs().GetUintOrZero("since"))
-
+	// since - идентификатор сообщения, после которого будут выводиться записи
+	// (сообщение с данным идентификатором в результат не попадает).
+	//
 	// Вид сортировки:
 	// flat - по дате, комментарии выводятся простым списком в порядке создания;
 	// tree - древовидный, комментарии выводятся отсортированные в дереве
@@ -137,15 +170,17 @@ func (h *Handlers) GetPosts(ctx *fasthttp.RequestCtx) {
 	// Available values : flat, tree, parent_tree
 	//
 	// Default value : flat
-
-	sort := string(ctx.QueryArgs().Peek("sort"))
-
-	// Флаг сортировки по убыванию.
-	desc := ctx.QueryArgs().GetBool("desc")
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{
+		Since: httputils.SinceID,
+		Sorts: []string{"flat", "tree", "parent_tree"},
+	})
+	if !ok {
+		return
+	}
 
 	var posts models.PostList
 	var err error
-	posts, err = h.useCase.GetPosts(idOrSlug, limit, since, sort, desc)
+	posts, err = h.useCase.GetPosts(idOrSlug, int64(page.Limit), int64(page.SinceID), page.Sort, page.Desc, middleware.Actor(ctx))
 
 	if errors.Is(err, customErr.ErrThreadNotFound) {
 		resp := map[string]string{
@@ -170,10 +205,16 @@ func (h *Handlers) VoteThread(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	var ok bool
+	if vote.Nickname, ok = middleware.BindAuthor(ctx, vote.Nickname); !ok {
+		middleware.RespondAuthorMismatch(ctx, vote.Nickname)
+		return
+	}
+
 	idOrSlug := ctx.UserValue("slug_or_id").(string)
 	nickname := vote.Nickname
 
-	thread, err := h.useCase.VoteThread(idOrSlug, vote)
+	thread, err := h.useCase.VoteThread(idOrSlug, vote, middleware.Actor(ctx))
 
 	if errors.Is(err, customErr.ErrThreadNotFound) {
 		resp := map[string]string{
@@ -189,6 +230,33 @@ func (h *Handlers) VoteThread(ctx *fasthttp.RequestCtx) {
 		httputils.RespondErr(ctx, http.StatusNotFound, resp)
 		return
 	}
+	if httputils.RespondBanned(ctx, err) {
+		return
+	}
+	if errors.Is(err, customErr.ErrSelfVote) {
+		resp := map[string]string{
+			"code":    "self_vote",
+			"message": "User can't vote for own thread: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrVoteNotAllowed) {
+		resp := map[string]string{
+			"code":    "vote_not_allowed",
+			"message": "User is not allowed to vote yet: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrVoteRateLimited) {
+		resp := map[string]string{
+			"code":    "vote_rate_limited",
+			"message": "Too many vote changes by user: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusTooManyRequests, resp)
+		return
+	}
 	if err != nil {
 		httputils.Respond(ctx, http.StatusInternalServerError, nil)
 		log.Println(err)
@@ -1,20 +1,29 @@
 package repository
 
 import (
+	auditRepo "DBForum/internal/app/audit/repository"
+	banRepo "DBForum/internal/app/ban/repository"
+	"DBForum/internal/app/config"
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/models"
+	reportRepo "DBForum/internal/app/report/repository"
 	"github.com/jackc/pgx"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
+	threadColumns = "id, forum_slug, author_nickname, title, message, votes, COALESCE(slug, '') as slug, created, edit_count, last_edited"
+
 	insertThread = `INSERT INTO dbforum.thread(
 							   forum_slug, 
 							   author_nickname, 
 							   title, 
 							   message, 
 							   slug, 
-							   created
+							   created,
+							   hidden
                            ) 
                            VALUES (
                                    $1, 
@@ -22,23 +31,46 @@ const (
                                    $3, 
                                    $4, 
                                    NULLIF($5,''), 
-                                   $6) RETURNING ID`
+                                   $6,
+                                   $7) RETURNING ID`
+
+	selectThreadBySlug = "SELECT " + threadColumns + ", hidden FROM dbforum.thread WHERE slug = $1"
+
+	selectThreadsByForumSlugSinceDesc = "SELECT " + threadColumns + " FROM dbforum.thread WHERE forum_slug = $1 AND NOT hidden AND created <= $2 ORDER BY created DESC LIMIT $3"
+
+	selectThreadsByForumSlugSince = "SELECT " + threadColumns + " FROM dbforum.thread WHERE forum_slug = $1 AND NOT hidden AND created >= $2 ORDER BY created LIMIT $3"
+
+	selectThreadsByForumSlugDesc = "SELECT " + threadColumns + " FROM dbforum.thread WHERE forum_slug = $1 AND NOT hidden ORDER BY created DESC LIMIT $2"
+
+	selectThreadsByForumSlug = "SELECT " + threadColumns + " FROM dbforum.thread WHERE forum_slug = $1 AND NOT hidden ORDER BY created LIMIT $2"
 
-	selectThreadBySlug = "SELECT id, forum_slug, author_nickname, title, message, votes,  COALESCE(slug, '') as slug, created FROM dbforum.thread WHERE slug = $1"
+	selectThreadByID = "SELECT " + threadColumns + ", hidden FROM dbforum.thread WHERE id = $1"
 
-	selectThreadsByForumSlugSinceDesc = "SELECT id, forum_slug, author_nickname, title, message, votes, COALESCE(slug, '') as slug, created FROM dbforum.thread WHERE forum_slug = $1 AND created <= $2 ORDER BY created DESC LIMIT $3"
+	updateThreadBySlug = "UPDATE dbforum.thread SET title=COALESCE(NULLIF($1, ''), title), message=COALESCE(NULLIF($2, ''), message) WHERE slug=$3 RETURNING " + threadColumns
 
-	selectThreadsByForumSlugSince = "SELECT id, forum_slug, author_nickname, title, message, votes, COALESCE(slug, '') as slug, created FROM dbforum.thread WHERE forum_slug = $1 AND created >= $2 ORDER BY created LIMIT $3"
+	updateThreadByID = "UPDATE dbforum.thread SET title=COALESCE(NULLIF($1, ''), title), message=COALESCE(NULLIF($2, ''), message) WHERE id=$3 RETURNING " + threadColumns
 
-	selectThreadsByForumSlugDesc = "SELECT id, forum_slug, author_nickname, title, message, votes, COALESCE(slug, '') as slug, created FROM dbforum.thread WHERE forum_slug = $1 ORDER BY created DESC LIMIT $2"
+	selectThreadsByAuthor = "SELECT " + threadColumns + " FROM dbforum.thread " +
+		"WHERE author_nickname = $1 AND NOT hidden " +
+		"AND ($2::text = '' OR forum_slug = $2::citext) " +
+		"AND ($3::bigint = 0 OR (created, id) > (SELECT created, id FROM dbforum.thread WHERE id = $3)) " +
+		"ORDER BY created, id LIMIT $4"
 
-	selectThreadsByForumSlug = "SELECT id, forum_slug, author_nickname, title, message, votes, COALESCE(slug, '') as slug, created FROM dbforum.thread WHERE forum_slug = $1 ORDER BY created LIMIT $2"
+	// Выгрузка пользователя включает и его скрытые ветки.
+	selectThreadsForExport = "SELECT " + threadColumns + ", hidden FROM dbforum.thread " +
+		"WHERE author_nickname = $1 AND id > $2 ORDER BY id LIMIT $3"
 
-	selectThreadByID = "SELECT id, forum_slug, author_nickname, title, message, votes, COALESCE(slug,'') as slug, created from dbforum.thread WHERE id = $1"
+	selectThreadsByAuthorDesc = "SELECT " + threadColumns + " FROM dbforum.thread " +
+		"WHERE author_nickname = $1 AND NOT hidden " +
+		"AND ($2::text = '' OR forum_slug = $2::citext) " +
+		"AND ($3::bigint = 0 OR (created, id) < (SELECT created, id FROM dbforum.thread WHERE id = $3)) " +
+		"ORDER BY created DESC, id DESC LIMIT $4"
 
-	updateThreadBySlug = "UPDATE dbforum.thread SET title=COALESCE(NULLIF($1, ''), title), message=COALESCE(NULLIF($2, ''), message) WHERE slug=$3 RETURNING *"
+	selectThreadEditInfoByID = "SELECT t.author_nickname, t.forum_slug, t.created, u.password <> '' " +
+		"FROM dbforum.thread AS t JOIN dbforum.users AS u ON u.nickname = t.author_nickname WHERE t.id = $1"
 
-	updateThreadByID = "UPDATE dbforum.thread SET title=COALESCE(NULLIF($1, ''), title), message=COALESCE(NULLIF($2, ''), message) WHERE id=$3 RETURNING *"
+	selectThreadEditInfoBySlug = "SELECT t.author_nickname, t.forum_slug, t.created, u.password <> '' " +
+		"FROM dbforum.thread AS t JOIN dbforum.users AS u ON u.nickname = t.author_nickname WHERE t.slug = $1"
 
 	selectVoteInfo = "SELECT nickname, voice FROM dbforum.votes WHERE thread_id = $1 AND nickname = $2"
 
@@ -50,6 +82,17 @@ const (
 
 	updateUserVote = "UPDATE dbforum.votes SET voice=$1 WHERE thread_id = $2 AND nickname = $3"
 
+	// Число сообщений берётся из счётчика users.posts, который ведут триггеры.
+	selectVoterInfo = "SELECT created, posts FROM dbforum.users WHERE nickname = $1"
+
+	// Смены голоса одного пользователя подсчитываются и записываются по
+	// очереди, иначе параллельные смены увидят одно и то же число.
+	lockVoteChanges = "SELECT pg_advisory_xact_lock(hashtext(lower($1)))"
+
+	countVoteChanges = "SELECT COUNT(*) FROM dbforum.vote_changes WHERE nickname = $1 AND changed > $2"
+
+	insertVoteChange = "INSERT INTO dbforum.vote_changes(nickname, thread_id) VALUES ($1, $2)"
+
 	selectSlugBySlug = "SELECT slug  as slug FROM dbforum.forum WHERE slug = $1"
 
 	selectNicknameByNickname = "SELECT nickname FROM dbforum.users WHERE nickname = $1"
@@ -65,8 +108,8 @@ func NewRepo(db *pgx.ConnPool) *Repository {
 	}
 }
 
-func (r *Repository) CreateThread(thread *models.Thread) (*models.Thread, error) {
-	tx, err := r.db.Begin()
+func (r *Repository) CreateThread(thread *models.Thread, actor models.Actor) (*models.Thread, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +128,10 @@ func (r *Repository) CreateThread(thread *models.Thread) (*models.Thread, error)
 			&thread.Message,
 			&thread.Votes,
 			&thread.Slug,
-			&thread.Created)
+			&thread.Created,
+			&thread.EditCount,
+			&thread.LastEdited,
+			&thread.Held)
 		if err != nil {
 			_ = tx.Rollback()
 			return nil, err
@@ -133,6 +179,11 @@ func (r *Repository) CreateThread(thread *models.Thread) (*models.Thread, error)
 
 	thread.Author = nickname
 
+	if err = banRepo.CheckBan(tx, thread.Author, thread.Forum); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
 	err = tx.QueryRow(
 		"insertThread",
 		thread.Forum,
@@ -140,7 +191,8 @@ func (r *Repository) CreateThread(thread *models.Thread) (*models.Thread, error)
 		thread.Title,
 		thread.Message,
 		thread.Slug,
-		thread.Created).Scan(&thread.ID)
+		thread.Created,
+		thread.Held).Scan(&thread.ID)
 
 	if driverErr, ok := err.(pgx.PgError); ok {
 		if driverErr.Code == "23505" {
@@ -152,6 +204,12 @@ func (r *Repository) CreateThread(thread *models.Thread) (*models.Thread, error)
 		_ = tx.Rollback()
 		return nil, err
 	}
+	if thread.Held {
+		if err = reportRepo.Hold(tx, "thread", thread.ID, thread.Forum, thread.Author, thread.HoldReason); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
 	if err = tx.Commit(); err != nil {
 		_ = tx.Rollback()
 		return nil, err
@@ -176,7 +234,10 @@ func (r *Repository) FindThreadBySlug(threadSlug string) (*models.Thread, error)
 		&thread.Message,
 		&thread.Votes,
 		&thread.Slug,
-		&thread.Created)
+		&thread.Created,
+		&thread.EditCount,
+		&thread.LastEdited,
+		&thread.Held)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +262,10 @@ func (r *Repository) FindThreadByID(id uint64) (*models.Thread, error) {
 		&thread.Message,
 		&thread.Votes,
 		&thread.Slug,
-		&thread.Created)
+		&thread.Created,
+		&thread.EditCount,
+		&thread.LastEdited,
+		&thread.Held)
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +316,9 @@ func (r *Repository) GetForumThreads(forumSlug string, limit int, since string,
 			&th.Message,
 			&th.Votes,
 			&th.Slug,
-			&th.Created)
+			&th.Created,
+			&th.EditCount,
+			&th.LastEdited)
 		if err != nil {
 			_ = tx.Rollback()
 			return nil, err
@@ -268,8 +334,115 @@ func (r *Repository) GetForumThreads(forumSlug string, limit int, since string,
 	return threads, nil
 }
 
-func (r *Repository) UpdateThreadBySlug(threadSlug string, thread models.Thread) (models.Thread, error) {
+func (r *Repository) GetUserThreads(nickname string, forumSlug string, limit int, since uint64, desc bool) ([]models.Thread, error) {
 	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	row, err := tx.Query("selectNicknameByNickname", nickname)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if !row.Next() {
+		row.Close()
+		_ = tx.Rollback()
+		return nil, customErr.ErrUserNotFound
+	}
+	row.Close()
+	if desc {
+		row, err = tx.Query("selectThreadsByAuthorDesc", nickname, forumSlug, since, limit)
+	} else {
+		row, err = tx.Query("selectThreadsByAuthor", nickname, forumSlug, since, limit)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var threads []models.Thread
+	for row.Next() {
+		th := models.Thread{}
+		err := row.Scan(
+			&th.ID,
+			&th.Forum,
+			&th.Author,
+			&th.Title,
+			&th.Message,
+			&th.Votes,
+			&th.Slug,
+			&th.Created,
+			&th.EditCount,
+			&th.LastEdited)
+		if err != nil {
+			row.Close()
+			_ = tx.Rollback()
+			return nil, err
+		}
+		threads = append(threads, th)
+	}
+	row.Close()
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return threads, nil
+}
+
+// ExportUserThreads ветки пользователя для выгрузки по возрастанию id,
+// вместе со скрытыми.
+func (r *Repository) ExportUserThreads(nickname string, limit int, since uint64) ([]models.Thread, error) {
+	rows, err := r.db.Query("selectThreadsForExport", nickname, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var threads []models.Thread
+	for rows.Next() {
+		th := models.Thread{}
+		err := rows.Scan(
+			&th.ID,
+			&th.Forum,
+			&th.Author,
+			&th.Title,
+			&th.Message,
+			&th.Votes,
+			&th.Slug,
+			&th.Created,
+			&th.EditCount,
+			&th.LastEdited,
+			&th.Held)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, th)
+	}
+	return threads, rows.Err()
+}
+
+func (r *Repository) GetThreadEditInfo(idOrSlug string) (models.EditInfo, error) {
+	var info models.EditInfo
+	var row *pgx.Row
+	if id, err := strconv.ParseUint(idOrSlug, 10, 64); err == nil {
+		row = r.db.QueryRow("selectThreadEditInfoByID", id)
+	} else {
+		row = r.db.QueryRow("selectThreadEditInfoBySlug", idOrSlug)
+	}
+	err := row.Scan(
+		&info.Author,
+		&info.Forum,
+		&info.Created,
+		&info.Protected)
+	if err == pgx.ErrNoRows {
+		return models.EditInfo{}, customErr.ErrThreadNotFound
+	}
+	if err != nil {
+		return models.EditInfo{}, err
+	}
+	return info, nil
+}
+
+func (r *Repository) UpdateThreadBySlug(threadSlug string, thread models.Thread, actor models.Actor) (models.Thread, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return models.Thread{}, err
 	}
@@ -281,7 +454,9 @@ func (r *Repository) UpdateThreadBySlug(threadSlug string, thread models.Thread)
 		&thread.Message,
 		&thread.Votes,
 		&thread.Slug,
-		&thread.Created)
+		&thread.Created,
+		&thread.EditCount,
+		&thread.LastEdited)
 	if err != nil {
 		_ = tx.Rollback()
 		return models.Thread{}, customErr.ErrThreadNotFound
@@ -293,8 +468,8 @@ func (r *Repository) UpdateThreadBySlug(threadSlug string, thread models.Thread)
 	return thread, nil
 }
 
-func (r *Repository) UpdateThreadByID(threadID uint64, thread models.Thread) (models.Thread, error) {
-	tx, err := r.db.Begin()
+func (r *Repository) UpdateThreadByID(threadID uint64, thread models.Thread, actor models.Actor) (models.Thread, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return models.Thread{}, err
 	}
@@ -306,7 +481,9 @@ func (r *Repository) UpdateThreadByID(threadID uint64, thread models.Thread) (mo
 		&thread.Message,
 		&thread.Votes,
 		&thread.Slug,
-		&thread.Created)
+		&thread.Created,
+		&thread.EditCount,
+		&thread.LastEdited)
 	if err != nil {
 		_ = tx.Rollback()
 		return models.Thread{}, customErr.ErrThreadNotFound
@@ -318,9 +495,9 @@ func (r *Repository) UpdateThreadByID(threadID uint64, thread models.Thread) (mo
 	return thread, nil
 }
 
-func (r *Repository) VoteThreadByID(idOrSlug string, vote models.Vote) (models.Thread, error) {
+func (r *Repository) VoteThreadByID(idOrSlug string, vote models.Vote, policy config.VotePolicy, actor models.Actor) (models.Thread, error) {
 	var thread models.Thread
-	tx, err := r.db.Begin()
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return models.Thread{}, err
 	}
@@ -347,12 +524,52 @@ func (r *Repository) VoteThreadByID(idOrSlug string, vote models.Vote) (models.T
 		&thread.Message,
 		&thread.Votes,
 		&thread.Slug,
-		&thread.Created)
+		&thread.Created,
+		&thread.EditCount,
+		&thread.LastEdited,
+		&thread.Held)
 	rows.Close()
 	if err != nil {
 		_ = tx.Rollback()
 		return models.Thread{}, err
 	}
+	// Скрытая ветка голосов не принимает.
+	if thread.Held {
+		_ = tx.Rollback()
+		return models.Thread{}, customErr.ErrThreadNotFound
+	}
+	if err = banRepo.CheckBan(tx, vote.Nickname, thread.Forum); err != nil {
+		_ = tx.Rollback()
+		return models.Thread{}, err
+	}
+	if policy.RejectSelfVotes && strings.EqualFold(thread.Author, vote.Nickname) {
+		_ = tx.Rollback()
+		return models.Thread{}, customErr.ErrSelfVote
+	}
+	if policy.MinAccountAge > 0 || policy.MinPosts > 0 {
+		var created time.Time
+		var posts int
+		rows, err = tx.Query("selectVoterInfo", vote.Nickname)
+		if err != nil {
+			_ = tx.Rollback()
+			return models.Thread{}, err
+		}
+		if !rows.Next() {
+			rows.Close()
+			_ = tx.Rollback()
+			return models.Thread{}, customErr.ErrUserNotFound
+		}
+		err = rows.Scan(&created, &posts)
+		rows.Close()
+		if err != nil {
+			_ = tx.Rollback()
+			return models.Thread{}, err
+		}
+		if time.Since(created) < policy.MinAccountAge || posts < policy.MinPosts {
+			_ = tx.Rollback()
+			return models.Thread{}, customErr.ErrVoteNotAllowed
+		}
+	}
 	curVote := models.Vote{}
 	rows, err = tx.Query("selectVoteInfo", thread.ID, vote.Nickname)
 	if err != nil {
@@ -385,6 +602,28 @@ func (r *Repository) VoteThreadByID(idOrSlug string, vote models.Vote) (models.T
 		_ = tx.Rollback()
 		return thread, nil
 	}
+	// Смены голоса записываются, только когда их число ограничено.
+	if policy.MaxChanges > 0 {
+		if _, err = tx.Exec("lockVoteChanges", vote.Nickname); err != nil {
+			_ = tx.Rollback()
+			return models.Thread{}, err
+		}
+		var changes int
+		err = tx.QueryRow("countVoteChanges", vote.Nickname, time.Now().Add(-policy.ChangesWindow)).Scan(&changes)
+		if err != nil {
+			_ = tx.Rollback()
+			return models.Thread{}, err
+		}
+		if changes >= policy.MaxChanges {
+			_ = tx.Rollback()
+			return models.Thread{}, customErr.ErrVoteRateLimited
+		}
+		_, err = tx.Exec("insertVoteChange", vote.Nickname, thread.ID)
+		if err != nil {
+			_ = tx.Rollback()
+			return models.Thread{}, err
+		}
+	}
 	thread.Votes -= curVote.Voice
 	thread.Votes += vote.Voice
 	_, err = tx.Exec("updateUserVote", vote.Voice, thread.ID, vote.Nickname)
@@ -418,6 +657,16 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
+	_, err = r.db.Prepare("selectThreadEditInfoByID", selectThreadEditInfoByID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectThreadEditInfoBySlug", selectThreadEditInfoBySlug)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Prepare("updateThreadBySlug", updateThreadBySlug)
 	if err != nil {
 		return err
@@ -448,6 +697,41 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
+	_, err = r.db.Prepare("selectThreadsByAuthor", selectThreadsByAuthor)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectThreadsByAuthorDesc", selectThreadsByAuthorDesc)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectThreadsForExport", selectThreadsForExport)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("lockVoteChanges", lockVoteChanges)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectVoterInfo", selectVoterInfo)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("countVoteChanges", countVoteChanges)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("insertVoteChange", insertVoteChange)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.Prepare("selectThreadsByForumSlugDesc", selectThreadsByForumSlugDesc)
 	if err != nil {
 		return err
@@ -1,25 +1,40 @@
 package usecase
 
 import (
+	blockUseCase "DBForum/internal/app/block/usecase"
+	"DBForum/internal/app/config"
+	customErr "DBForum/internal/app/errors"
+	filterUseCase "DBForum/internal/app/filter/usecase"
 	"DBForum/internal/app/models"
 	postRepo "DBForum/internal/app/post/repository"
+	roleUseCase "DBForum/internal/app/role/usecase"
 	threadRepo "DBForum/internal/app/thread/repository"
+	"errors"
 	"strconv"
 )
 
 type UseCase struct {
 	threadRepo threadRepo.Repository
 	postRepo   postRepo.Repository
+	votePolicy config.VotePolicy
+	editPolicy config.EditPolicy
+	filters    filterUseCase.UseCase
+	blocks     blockUseCase.UseCase
 }
 
-func NewUseCase(threadRepo threadRepo.Repository, postRepo postRepo.Repository) *UseCase {
+func NewUseCase(threadRepo threadRepo.Repository, postRepo postRepo.Repository, votePolicy config.VotePolicy, editPolicy config.EditPolicy, filters filterUseCase.UseCase, blocks blockUseCase.UseCase) *UseCase {
 	return &UseCase{
 		threadRepo: threadRepo,
 		postRepo:   postRepo,
+		votePolicy: votePolicy,
+		editPolicy: editPolicy,
+		filters:    filters,
+		blocks:     blocks,
 	}
 }
 
-func (u *UseCase) ThreadInfo(idOrSlug string) (*models.Thread, error) {
+// FindThread ищет ветку по id или slug без проверки видимости.
+func (u *UseCase) FindThread(idOrSlug string) (*models.Thread, error) {
 	var id uint64
 	var err error
 	if id, err = strconv.ParseUint(idOrSlug, 10, 64); err != nil {
@@ -36,33 +51,74 @@ func (u *UseCase) ThreadInfo(idOrSlug string) (*models.Thread, error) {
 	return thread, nil
 }
 
-func (u *UseCase) ChangeThread(idOrSlug string, thread models.Thread) (models.Thread, error) {
+// ThreadInfo ветка, видимая actor: скрытую видят только автор и модераторы
+// форума, остальным она не найдена.
+func (u *UseCase) ThreadInfo(idOrSlug string, actor models.Actor) (*models.Thread, error) {
+	thread, err := u.FindThread(idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	if !canView(actor, thread) {
+		return nil, customErr.ErrForumNotFound
+	}
+	return thread, nil
+}
+
+func canView(actor models.Actor, thread *models.Thread) bool {
+	return roleUseCase.CanView(actor, models.Visibility{Author: thread.Author, Forum: thread.Forum, Hidden: thread.Held})
+}
+
+// ChangeThread изменяет заголовок и текст ветки с проверкой права на правку.
+func (u *UseCase) ChangeThread(idOrSlug string, thread models.Thread, actor models.Actor) (models.Thread, error) {
+	info, err := u.threadRepo.GetThreadEditInfo(idOrSlug)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := roleUseCase.CanEdit(actor, info, u.editPolicy.Window); err != nil {
+		return models.Thread{}, err
+	}
 	var id uint64
-	var err error
 	if id, err = strconv.ParseUint(idOrSlug, 10, 64); err != nil {
-		thread, err = u.threadRepo.UpdateThreadBySlug(idOrSlug, thread)
+		thread, err = u.threadRepo.UpdateThreadBySlug(idOrSlug, thread, actor)
 		if err != nil {
 			return models.Thread{}, err
 		}
 		return thread, nil
 	}
-	thread, err = u.threadRepo.UpdateThreadByID(id, thread)
+	thread, err = u.threadRepo.UpdateThreadByID(id, thread, actor)
 	if err != nil {
 		return models.Thread{}, err
 	}
 	return thread, nil
 }
 
-func (u *UseCase) VoteThread(idOrSlug string, vote models.Vote) (models.Thread, error) {
-	thread, err := u.threadRepo.VoteThreadByID(idOrSlug, vote)
+func (u *UseCase) VoteThread(idOrSlug string, vote models.Vote, actor models.Actor) (models.Thread, error) {
+	thread, err := u.threadRepo.VoteThreadByID(idOrSlug, vote, u.votePolicy, actor)
 	if err != nil {
 		return models.Thread{}, err
 	}
 	return thread, nil
 }
 
-func (u *UseCase) CreatePosts(idOrSlug string, posts []models.Post) ([]models.Post, error) {
-	posts, err := u.postRepo.CreatePosts(idOrSlug, posts)
+// CreatePosts проверяет пакет фильтрами содержимого форума ветки и
+// сохраняет его. Ошибки несуществующей ветки возвращает сохранение,
+// в скрытую ветку сообщения не принимаются.
+func (u *UseCase) CreatePosts(idOrSlug string, posts []models.Post, actor models.Actor) ([]models.Post, error) {
+	if len(posts) > 0 {
+		thread, err := u.FindThread(idOrSlug)
+		switch {
+		case err == nil:
+			if thread.Held {
+				return nil, customErr.ErrThreadNotFound
+			}
+			if err := u.filters.CheckPosts(thread.Forum, posts); err != nil {
+				return nil, err
+			}
+		case !errors.Is(err, customErr.ErrThreadNotFound) && !errors.Is(err, customErr.ErrForumNotFound):
+			return nil, err
+		}
+	}
+	posts, err := u.postRepo.CreatePosts(idOrSlug, posts, actor)
 	if err != nil {
 		return nil, err
 	}
@@ -72,11 +128,27 @@ func (u *UseCase) CreatePosts(idOrSlug string, posts []models.Post) ([]models.Po
 	return posts, nil
 }
 
-func (u *UseCase) GetPosts(idOrSlug string, limit int64, since int64, sort string, desc bool) ([]models.Post, error) {
+// GetPosts сообщения ветки, видимой actor; сообщения пользователей,
+// заблокированных actor, свёрнуты или убраны.
+func (u *UseCase) GetPosts(idOrSlug string, limit int64, since int64, sort string, desc bool, actor models.Actor) ([]models.Post, error) {
+	thread, err := u.FindThread(idOrSlug)
+	switch {
+	case err == nil:
+		if !canView(actor, thread) {
+			return nil, customErr.ErrThreadNotFound
+		}
+	case errors.Is(err, customErr.ErrForumNotFound):
+		return nil, customErr.ErrThreadNotFound
+	default:
+		return nil, err
+	}
 	posts, err := u.postRepo.GetPosts(idOrSlug, limit, since, desc, sort)
 	if err != nil {
 		return nil, err
 	}
+	if posts, err = u.blocks.FilterPosts(actor.Nickname, posts); err != nil {
+		return nil, err
+	}
 	if posts == nil {
 		return []models.Post{}, nil
 	}
@@ -3,8 +3,12 @@ package handlers
 import (
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/httputils"
+	"DBForum/internal/app/middleware"
 	"DBForum/internal/app/models"
+	roleUseCase "DBForum/internal/app/role/usecase"
 	userUseCase "DBForum/internal/app/user/usecase"
+	"archive/zip"
+	"bytes"
 	"errors"
 	"github.com/mailru/easyjson"
 	"github.com/valyala/fasthttp"
@@ -14,11 +18,13 @@ import (
 
 type Handlers struct {
 	useCase userUseCase.UseCase
+	pager   httputils.Pager
 }
 
-func NewHandler(useCase userUseCase.UseCase) *Handlers {
+func NewHandler(useCase userUseCase.UseCase, pager httputils.Pager) *Handlers {
 	return &Handlers{
 		useCase: useCase,
+		pager:   pager,
 	}
 }
 
@@ -31,8 +37,34 @@ func (h *Handlers) CreateUser(ctx *fasthttp.RequestCtx) {
 		log.Println(err)
 		return
 	}
+	// Необязательный пароль для входа через /api/auth/login.
+	var creds models.Credentials
+	if err := easyjson.Unmarshal(ctx.PostBody(), &creds); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
 
-	err := h.useCase.CreateUser(user)
+	err := h.useCase.CreateUser(&user, creds.Password, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrInvalidWebsite) {
+		respondInvalidWebsite(ctx)
+		return
+	}
+	if errors.Is(err, customErr.ErrEmailReserved) {
+		respondEmailReserved(ctx)
+		return
+	}
+	if errors.Is(err, customErr.ErrInvalidNickname) {
+		resp := map[string]string{
+			"message": "Nickname must consist of letters, digits, '_' and '.' and not start with 'deleted-'",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrNicknameReserved) {
+		respondNicknameReserved(ctx, user.Nickname)
+		return
+	}
 	if errors.Is(err, customErr.ErrDuplicate) {
 		var users models.UserList
 		users, err = h.useCase.GetUsersByNickAndEmail(user.Nickname, user.Email)
@@ -56,7 +88,7 @@ func (h *Handlers) GetUserInfo(ctx *fasthttp.RequestCtx) {
 	nickname := ctx.UserValue("nickname").(string)
 	user := &models.User{Nickname: nickname}
 
-	user, err := h.useCase.GetUserInfo(nickname)
+	user, err := h.useCase.GetUserInfo(nickname, middleware.Caller(ctx))
 
 	if errors.Is(err, customErr.ErrUserNotFound) {
 		resp := map[string]string{
@@ -74,30 +106,317 @@ func (h *Handlers) GetUserInfo(ctx *fasthttp.RequestCtx) {
 	httputils.Respond(ctx, http.StatusOK, user)
 }
 
+// ChangeUser меняет только переданные в теле поля профиля; поле privacy
+// частично обновляет настройки приватности.
 func (h *Handlers) ChangeUser(ctx *fasthttp.RequestCtx) {
 	nickname := ctx.UserValue("nickname").(string)
 
-	user := models.User{Nickname: nickname}
-	if err := easyjson.Unmarshal(ctx.PostBody(), &user); err != nil {
+	var update models.UserUpdate
+	if err := easyjson.Unmarshal(ctx.PostBody(), &update); err != nil {
 		httputils.Respond(ctx, http.StatusInternalServerError, nil)
 		log.Println(err)
 		return
 	}
+	var creds models.Credentials
+	if err := easyjson.Unmarshal(ctx.PostBody(), &creds); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	user, err := h.useCase.ChangeUser(nickname, update, creds.Password, middleware.Actor(ctx))
+	if h.respondProfileErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, user)
+}
+
+// RenameUser меняет никнейм на {"nickname": ...} и отвечает профилем под
+// новым никнеймом. Сессии пользователя отзываются.
+func (h *Handlers) RenameUser(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	var rename models.Rename
+	if err := easyjson.Unmarshal(ctx.PostBody(), &rename); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+
+	user, err := h.useCase.RenameUser(nickname, rename, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrInvalidNickname) {
+		resp := map[string]string{
+			"message": "New nickname must consist of letters, digits, '_' and '.' and not start with 'deleted-'",
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrNicknameTaken) {
+		resp := map[string]string{
+			"message": "Nickname is already taken: " + rename.Nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusConflict, resp)
+		return
+	}
+	if errors.Is(err, customErr.ErrNicknameReserved) {
+		respondNicknameReserved(ctx, rename.Nickname)
+		return
+	}
+	if h.respondProfileErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, user)
+}
+
+// DeleteUser удаляет аккаунт: записи остаются под псевдонимом, личные данные
+// стираются. Отвечает обезличенным профилем.
+func (h *Handlers) DeleteUser(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	user, err := h.useCase.DeleteUser(nickname, middleware.Actor(ctx))
+	if h.respondProfileErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, user)
+}
+
+// ExportUser отдаёт zip-архив с profile.json, posts.json, threads.json и
+// votes.json.
+func (h *Handlers) ExportUser(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	export, err := h.useCase.ExportUser(nickname, middleware.Actor(ctx))
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't export data of another user: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+	if h.respondProfileErr(ctx, nickname, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data easyjson.Marshaler
+	}{
+		{"profile.json", export.Profile},
+		{"posts.json", export.Posts},
+		{"threads.json", export.Threads},
+		{"votes.json", export.Votes},
+	}
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err == nil {
+			_, err = easyjson.MarshalToWriter(file.data, w)
+		}
+		if err != nil {
+			httputils.Respond(ctx, http.StatusInternalServerError, nil)
+			log.Println(err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	ctx.SetStatusCode(http.StatusOK)
+	ctx.SetContentType("application/zip")
+	ctx.Response.Header.Set("Content-Disposition", `attachment; filename="`+export.Profile.Nickname+`.zip"`)
+	ctx.SetBody(buf.Bytes())
+}
+
+// SetAvatar принимает изображение PNG, JPEG, GIF или WebP телом запроса.
+func (h *Handlers) SetAvatar(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	user, err := h.useCase.SetAvatar(nickname, ctx.PostBody(), middleware.Actor(ctx))
+	if h.respondProfileErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, user)
+}
 
-	err := h.useCase.ChangeUser(&user)
+func (h *Handlers) DeleteAvatar(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+
+	user, err := h.useCase.DeleteAvatar(nickname, middleware.Actor(ctx))
+	if h.respondProfileErr(ctx, nickname, err) {
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, user)
+}
+
+func (h *Handlers) respondProfileErr(ctx *fasthttp.RequestCtx, nickname string, err error) bool {
+	if err == nil {
+		return false
+	}
 	if errors.Is(err, customErr.ErrUserNotFound) {
 		resp := map[string]string{
 			"message": "Can't find user by nickname: " + nickname,
 		}
 		httputils.RespondErr(ctx, http.StatusNotFound, resp)
-		return
+		return true
 	}
 	if errors.Is(err, customErr.ErrConflict) {
 		resp := map[string]string{
 			"message": "This email is already registered by user: ",
 		}
 		httputils.RespondErr(ctx, http.StatusConflict, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrForbidden) {
+		resp := map[string]string{
+			"message": "Can't change profile of another user: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrInvalidWebsite) {
+		respondInvalidWebsite(ctx)
+		return true
+	}
+	if errors.Is(err, customErr.ErrEmailReserved) {
+		respondEmailReserved(ctx)
+		return true
+	}
+	if errors.Is(err, customErr.ErrAvatarTooLarge) {
+		resp := map[string]string{
+			"message": "Avatar is too large",
+		}
+		httputils.RespondErr(ctx, http.StatusRequestEntityTooLarge, resp)
+		return true
+	}
+	if errors.Is(err, customErr.ErrAvatarType) {
+		resp := map[string]string{
+			"message": "Avatar must be a PNG, JPEG, GIF or WebP image",
+		}
+		httputils.RespondErr(ctx, http.StatusUnsupportedMediaType, resp)
+		return true
+	}
+	httputils.Respond(ctx, http.StatusInternalServerError, nil)
+	log.Println(err)
+	return true
+}
+
+func respondNicknameReserved(ctx *fasthttp.RequestCtx, nickname string) {
+	resp := map[string]string{
+		"message": "Nickname is reserved after a rename: " + nickname,
+	}
+	httputils.RespondErr(ctx, http.StatusConflict, resp)
+}
+
+func respondInvalidWebsite(ctx *fasthttp.RequestCtx) {
+	resp := map[string]string{
+		"message": "Website must be an http or https URL",
+	}
+	httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+}
+
+func respondEmailReserved(ctx *fasthttp.RequestCtx) {
+	resp := map[string]string{
+		"message": "Email addresses @invalid are reserved for deleted accounts",
+	}
+	httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+}
+
+func (h *Handlers) SearchUsers(ctx *fasthttp.RequestCtx) {
+	// Начало (или часть) никнейма либо полного имени.
+	query := string(ctx.QueryArgs().Peek("q"))
+	// Поиск по почте доступен только администраторам.
+	email := string(ctx.QueryArgs().Peek("email"))
+	// Вид сравнения: prefix, substring.
+	//
+	// Default value : prefix
+	match := string(ctx.QueryArgs().Peek("match"))
+	// since - никнейм пользователя, с которого будут выводиться пользователи
+	// (пользователь с данным никнеймом в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceNickname})
+	if !ok {
 		return
 	}
-	httputils.Respond(ctx, http.StatusOK, user)
+
+	if match != "" && match != "prefix" && match != "substring" {
+		resp := map[string]string{
+			"message": "Unknown match type: " + match,
+		}
+		httputils.RespondErr(ctx, http.StatusBadRequest, resp)
+		return
+	}
+	if email != "" && !middleware.Allowed(ctx, roleUseCase.PermAdmin, "") {
+		resp := map[string]string{
+			"message": "Search by email is available to administrators only",
+		}
+		httputils.RespondErr(ctx, http.StatusForbidden, resp)
+		return
+	}
+
+	var users models.UserList
+	users, err := h.useCase.SearchUsers(query, email, match, page.Limit, page.Since, page.Desc, middleware.Caller(ctx))
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, users)
+}
+
+func (h *Handlers) GetThreads(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	// Slug форума, которым ограничивается выборка.
+	forumSlug := string(ctx.QueryArgs().Peek("forum"))
+	// since - идентификатор ветки, после которой будут выводиться записи
+	// (ветка с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+
+	var threads models.ThreadList
+	threads, err := h.useCase.GetUserThreads(nickname, forumSlug, page.Limit, page.SinceID, page.Desc)
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, threads)
+}
+
+func (h *Handlers) GetPosts(ctx *fasthttp.RequestCtx) {
+	nickname := ctx.UserValue("nickname").(string)
+	// Slug форума, которым ограничивается выборка.
+	forumSlug := string(ctx.QueryArgs().Peek("forum"))
+	// since - идентификатор сообщения, после которого будут выводиться записи
+	// (сообщение с данным идентификатором в результат не попадает).
+	page, ok := h.pager.Parse(ctx, httputils.PageSpec{Since: httputils.SinceID})
+	if !ok {
+		return
+	}
+
+	var posts models.PostList
+	posts, err := h.useCase.GetUserPosts(nickname, forumSlug, page.Limit, page.SinceID, page.Desc)
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		resp := map[string]string{
+			"message": "Can't find user by nickname: " + nickname,
+		}
+		httputils.RespondErr(ctx, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		httputils.Respond(ctx, http.StatusInternalServerError, nil)
+		log.Println(err)
+		return
+	}
+	httputils.Respond(ctx, http.StatusOK, posts)
 }
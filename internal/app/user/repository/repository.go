@@ -1,64 +1,278 @@
 package repository
 
 import (
+	auditRepo "DBForum/internal/app/audit/repository"
 	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/models"
 	"github.com/jackc/pgx"
+	"strings"
+	"time"
 )
 
 const (
-	selectIDByNickname = "SELECT id FROM dbforum.users WHERE nickname = $1"
+	// Поля профиля после nickname, fullname, about, email, reputation; порядок
+	// совпадает с scanUser.
+	profileColumns = "avatar, signature, website, location, created, last_seen, posts, threads, " +
+		"hide_email, hide_website, hide_location, hide_last_seen, hide_counts, deleted IS NOT NULL"
 
-	selectUsersByForumSlugSinceDesc = "SELECT fu.nickname, fu.fullname, fu.about, fu.email " +
-		"FROM dbforum.forum_users AS fu " +
-		"WHERE fu.forum_slug = $1 AND fu.nickname < $2 " +
-		"ORDER BY fu.nickname DESC " +
-		"LIMIT $3"
+	userColumns = "nickname, fullname, about, email, reputation, " + profileColumns
 
-	selectUsersByForumSlugSince = "SELECT fu.nickname, fu.fullname, fu.about, fu.email " +
-		"FROM dbforum.forum_users AS fu " +
-		"WHERE fu.forum_slug = $1 " +
-		"AND fu.nickname > $2 " +
-		"ORDER BY fu.nickname " +
-		"LIMIT $3"
+	// Поля участника форума: профиль в порядке scanUser и активность в форуме.
+	forumUserColumns = "SELECT fu.nickname, fu.fullname, fu.about, fu.email, u.reputation, " +
+		"u.avatar, u.signature, u.website, u.location, u.created, u.last_seen, u.posts, u.threads, " +
+		"u.hide_email, u.hide_website, u.hide_location, u.hide_last_seen, u.hide_counts, u.deleted IS NOT NULL, " +
+		"fu.posts, fu.threads, fu.first_active, fu.last_active "
 
-	selectUsersByForumSlugDesc = "SELECT fu.nickname, fu.fullname, fu.about, fu.email " +
-		"FROM dbforum.forum_users AS fu " +
-		"WHERE fu.forum_slug = $1 " +
-		"ORDER BY fu.nickname DESC " +
-		"LIMIT $2"
+	// Фильтр участников форума по $2: moderators - модераторы форума и
+	// глобальные модераторы, banned - пользователи с действующим баном в форуме или глобальным.
+	forumUsersFilter = "AND ($2::text = '' " +
+		"OR ($2 = 'moderators' AND EXISTS (SELECT 1 FROM dbforum.roles AS r " +
+		"WHERE r.nickname = fu.nickname " +
+		"AND (r.role = 'moderator' OR r.role = 'forum_moderator' AND r.forum_slug = fu.forum_slug))) " +
+		"OR ($2 = 'banned' AND EXISTS (SELECT 1 FROM dbforum.bans AS b " +
+		"WHERE b.nickname = fu.nickname AND b.forum_slug IN ('', fu.forum_slug) " +
+		"AND (b.expires IS NULL OR b.expires > now())))) "
 
-	selectUsersByForumSlug = "SELECT fu.nickname, fu.fullname, fu.about, fu.email " +
-		"FROM dbforum.forum_users AS fu " +
-		"WHERE fu.forum_slug = $1 " +
-		"ORDER BY fu.nickname " +
-		"LIMIT $2"
+	selectIDByNickname = "SELECT id FROM dbforum.users WHERE nickname = $1"
 
 	insertUser = `INSERT INTO dbforum.users (
 							   nickname, 
 							   fullname, 
 							   about, 
-							   email
+							   email,
+							   password,
+							   signature,
+							   website,
+							   location
                            ) 
                            VALUES (
                                    $1,
                                    $2,
                                    $3,
-                                   $4)`
+                                   $4,
+                                   $5,
+                                   $6,
+                                   $7,
+                                   $8)`
 
-	selectUsersByNickAndEmail = "SELECT nickname, fullname, about, email FROM dbforum.users WHERE nickname = $1 OR email = $2"
+	selectUsersByNickAndEmail = "SELECT " + userColumns + " FROM dbforum.users WHERE nickname = $1 OR email = $2"
 
-	selectByNickname = "SELECT nickname, fullname, about, email FROM dbforum.users WHERE nickname = $1"
+	selectByNickname = "SELECT " + userColumns + " FROM dbforum.users WHERE nickname = $1"
 
+	// Отсутствующее в запросе поле передаётся как NULL и не меняется. Пустые
+	// fullname и email по-прежнему означают "не менять".
 	updateUser = `UPDATE dbforum.users SET 
 					fullname=COALESCE(NULLIF($1, ''), fullname),
-					about=COALESCE(NULLIF($2, ''), about),
-					email=COALESCE(NULLIF($3, ''), email)
-					WHERE nickname=$4 RETURNING nickname, fullname, about, email`
+					about=COALESCE($2, about),
+					email=COALESCE(NULLIF($3, ''), email),
+					password=COALESCE(NULLIF($5, ''), password),
+					signature=COALESCE($6, signature),
+					website=COALESCE($7, website),
+					location=COALESCE($8, location),
+					hide_email=COALESCE($9, hide_email),
+					hide_website=COALESCE($10, hide_website),
+					hide_location=COALESCE($11, hide_location),
+					hide_last_seen=COALESCE($12, hide_last_seen),
+					hide_counts=COALESCE($13, hide_counts)
+					WHERE nickname=$4 RETURNING ` + userColumns
+
+	// Возвращает прежний адрес аватара для удаления файла.
+	updateAvatar = `UPDATE dbforum.users AS u SET avatar = $2
+					FROM (SELECT nickname, avatar FROM dbforum.users WHERE nickname = $1 FOR UPDATE) AS old
+					WHERE u.nickname = old.nickname
+					RETURNING old.avatar`
+
+	// Каскадные изменения записей пользователя не попадают в журнал аудита.
+	setRenaming = "SELECT set_config('dbforum.renaming', 'on', true)"
+
+	renameUser = "UPDATE dbforum.users SET nickname = $2 WHERE nickname = $1 RETURNING " + userColumns
+
+	// Владелец прежнего никнейма и действует ли ещё резерв: он действует для
+	// переименований после $2.
+	selectReservation = "SELECT nickname, renamed > $2 FROM dbforum.user_renames WHERE old_nickname = $1"
+
+	deleteReservation = "DELETE FROM dbforum.user_renames WHERE old_nickname = $1"
+
+	selectRenamed = "SELECT nickname FROM dbforum.user_renames WHERE old_nickname = $1"
+
+	// Удалённые пользователи не входят и не меняют профиль.
+	selectPassword = "SELECT nickname, password FROM dbforum.users WHERE nickname = $1 AND deleted IS NULL"
+	// Без токена нельзя действовать от имени аккаунта с паролем и удалённого.
+	selectProtected = "SELECT password <> '' OR deleted IS NOT NULL FROM dbforum.users WHERE nickname = $1"
+
+	// Псевдоним и служебная почта строятся из id, чтобы быть уникальными.
+	// Возвращает прежний аватар для удаления файла.
+	anonymizeUser = `UPDATE dbforum.users AS u SET
+					nickname = 'deleted-' || u.id, email = 'deleted-' || u.id || '@invalid',
+					fullname = '', about = '', password = '', avatar = '', signature = '', website = '', location = '',
+					last_seen = NULL, hide_email = false, hide_website = false, hide_location = false,
+					hide_last_seen = false, hide_counts = false, deleted = now()
+					FROM (SELECT nickname, avatar FROM dbforum.users
+						WHERE nickname = $1 AND deleted IS NULL FOR UPDATE) AS old
+					WHERE u.nickname = old.nickname
+					RETURNING u.nickname, old.avatar`
+
+	deleteForumUsers        = "DELETE FROM dbforum.forum_users WHERE nickname = $1"
+	deleteUserRenames       = "DELETE FROM dbforum.user_renames WHERE nickname = $1"
+	deleteUserRoles         = "DELETE FROM dbforum.roles WHERE nickname = $1"
+	deleteUserThreadSubs    = "DELETE FROM dbforum.thread_subscriptions WHERE nickname = $1"
+	deleteUserForumSubs     = "DELETE FROM dbforum.forum_subscriptions WHERE nickname = $1"
+	deleteUserWatched       = "DELETE FROM dbforum.watched_visits WHERE nickname = $1"
+	deleteUserBlocks        = "DELETE FROM dbforum.user_blocks WHERE nickname = $1 OR blocked = $1"
+	deleteUserNotifications = "DELETE FROM dbforum.notifications WHERE nickname = $1"
+	deleteUserMentions      = "DELETE FROM dbforum.mentions WHERE nickname = $1"
+	leaveUserConversations  = "UPDATE dbforum.conversation_members SET left_at = now() WHERE nickname = $1 AND left_at IS NULL"
+
+	// Журнал аудита сохраняет действия, но не личные данные: действия
+	// переписываются на псевдоним, снимки профиля стираются. Триггер
+	// audit_log_protect пропускает эти изменения только после setAuditRewrite.
+	setAuditRewrite       = "SELECT set_config('dbforum.audit_rewrite', 'on', true)"
+	anonymizeAuditActor   = "UPDATE dbforum.audit_log SET actor = $2 WHERE actor = $1"
+	anonymizeAuditProfile = "UPDATE dbforum.audit_log SET target_id = $2, before = NULL, after = NULL " +
+		"WHERE target_type = 'user' AND target_id IN ($1, $2)"
+
+	selectUserVotes = "SELECT thread_id, voice FROM dbforum.votes WHERE nickname = $1 ORDER BY thread_id"
 
 	selectNickByEmail = "SELECT nickname FROM dbforum.users WHERE email = $1"
+
+	searchUsers = "SELECT " + userColumns + " " +
+		"FROM dbforum.users " +
+		"WHERE ($1::text = '' OR nickname::text ILIKE $1::text OR fullname ILIKE $1::text) " +
+		"AND ($2::text = '' OR email::text ILIKE $2::text) " +
+		"AND ($3::text = '' OR nickname > $3::citext) " +
+		"ORDER BY nickname " +
+		"LIMIT $4"
+
+	searchUsersDesc = "SELECT " + userColumns + " " +
+		"FROM dbforum.users " +
+		"WHERE ($1::text = '' OR nickname::text ILIKE $1::text OR fullname ILIKE $1::text) " +
+		"AND ($2::text = '' OR email::text ILIKE $2::text) " +
+		"AND ($3::text = '' OR nickname < $3::citext) " +
+		"ORDER BY nickname DESC " +
+		"LIMIT $4"
+
+	recomputeReputation = `UPDATE dbforum.users AS u SET reputation = s.reputation
+					FROM (SELECT usr.nickname, COALESCE(SUM(v.voice), 0) AS reputation
+						FROM dbforum.users AS usr
+						LEFT JOIN dbforum.thread AS t ON t.author_nickname = usr.nickname
+						LEFT JOIN dbforum.votes AS v ON v.thread_id = t.id
+						GROUP BY usr.nickname) AS s
+					WHERE s.nickname = u.nickname AND s.reputation <> u.reputation`
+
+	// Расхождения forum_users с users, thread и post: устаревшая копия
+	// профиля или активности, пропущенный автор и лишняя запись без веток и
+	// постов в форуме. Обезличенные аккаунты в списке участников не нужны.
+	forumUserActivity = `(SELECT forum_slug, author_nickname,
+							SUM(posts) AS posts, SUM(threads) AS threads,
+							MIN(created) AS first_active, MAX(created) AS last_active
+						FROM (SELECT forum_slug, author_nickname, 0 AS posts, 1 AS threads, created
+								FROM dbforum.thread
+							UNION ALL
+							SELECT forum_slug, author_nickname, 1, 0, created FROM dbforum.post) AS m
+						GROUP BY forum_slug, author_nickname)`
+	forumUserDiffers = `(fu.fullname, fu.about, fu.email, fu.posts, fu.threads, fu.first_active, fu.last_active)
+					IS DISTINCT FROM (u.fullname, u.about, u.email::text, a.posts::int, a.threads::int, a.first_active, a.last_active)`
+	forumUserStale = `SELECT fu.forum_slug, fu.nickname FROM dbforum.forum_users AS fu
+					JOIN dbforum.users AS u ON u.nickname = fu.nickname
+					JOIN ` + forumUserActivity + ` AS a
+						ON a.forum_slug = fu.forum_slug AND a.author_nickname = fu.nickname
+					WHERE ` + forumUserDiffers
+	forumUserMissing = `SELECT a.forum_slug, a.author_nickname, a.posts, a.threads, a.first_active, a.last_active
+					FROM ` + forumUserActivity + ` AS a
+					JOIN dbforum.users AS u ON u.nickname = a.author_nickname AND u.deleted IS NULL
+					WHERE NOT EXISTS (SELECT 1 FROM dbforum.forum_users AS fu
+						WHERE fu.forum_slug = a.forum_slug AND fu.nickname = a.author_nickname)`
+	forumUserOrphaned = `SELECT fu.forum_slug, fu.nickname FROM dbforum.forum_users AS fu
+					JOIN dbforum.users AS u ON u.nickname = fu.nickname
+					WHERE u.deleted IS NOT NULL
+					   OR NOT EXISTS (SELECT 1 FROM dbforum.thread AS t
+						WHERE t.forum_slug = fu.forum_slug AND t.author_nickname = fu.nickname)
+					  AND NOT EXISTS (SELECT 1 FROM dbforum.post AS p
+						WHERE p.forum_slug = fu.forum_slug AND p.author_nickname = fu.nickname)`
+
+	checkForumUsers = `SELECT forum_slug, nickname, 'stale' FROM (` + forumUserStale + `) AS s
+					UNION ALL
+					SELECT forum_slug, author_nickname, 'missing' FROM (` + forumUserMissing + `) AS m
+					UNION ALL
+					SELECT forum_slug, nickname, 'orphaned' FROM (` + forumUserOrphaned + `) AS o
+					ORDER BY 1, 2`
+	repairStaleForumUsers = `UPDATE dbforum.forum_users AS fu
+					SET fullname = u.fullname, about = u.about, email = u.email,
+						posts = a.posts, threads = a.threads,
+						first_active = a.first_active, last_active = a.last_active
+					FROM dbforum.users AS u, ` + forumUserActivity + ` AS a
+					WHERE u.nickname = fu.nickname
+					  AND a.forum_slug = fu.forum_slug AND a.author_nickname = fu.nickname
+					  AND ` + forumUserDiffers
+	repairMissingForumUsers = `INSERT INTO dbforum.forum_users(forum_slug, nickname, fullname, about, email,
+						posts, threads, first_active, last_active)
+					SELECT m.forum_slug, u.nickname, u.fullname, u.about, u.email,
+						m.posts, m.threads, m.first_active, m.last_active
+					FROM (` + forumUserMissing + `) AS m
+					JOIN dbforum.users AS u ON u.nickname = m.author_nickname
+					ON CONFLICT DO NOTHING`
+	repairOrphanedForumUsers = `DELETE FROM dbforum.forum_users
+					WHERE (forum_slug, nickname) IN (` + forumUserOrphaned + `)`
 )
 
+// forumUserSorts поле сортировки участников форума по значению параметра
+// sort. При равенстве участники упорядочены по никнейму, since остаётся
+// никнеймом: выборка продолжается с пары (поле, никнейм) указанного участника.
+var forumUserSorts = map[string]string{
+	"nickname":     "",
+	"reputation":   "u.reputation",
+	"posts":        "fu.posts",
+	"first_active": "fu.first_active",
+	"last_active":  "fu.last_active",
+	"joined":       "fu.first_active",
+}
+
+// forumUsersQuery запрос участников форума с сортировкой sort; параметры:
+// форум, фильтр, since (если задан) и limit.
+func forumUsersQuery(sort string, since, desc bool) string {
+	order, cmp := "", ">"
+	if desc {
+		order, cmp = " DESC", "<"
+	}
+	key, keys := forumUserSorts[sort], "fu.nickname"
+	if key != "" {
+		keys = key + ", fu.nickname"
+	}
+	query := forumUserColumns +
+		"FROM dbforum.forum_users AS fu " +
+		"JOIN dbforum.users AS u ON u.nickname = fu.nickname " +
+		"WHERE fu.forum_slug = $1 " + forumUsersFilter
+	limit := "$3"
+	if since {
+		if key == "" {
+			query += "AND fu.nickname " + cmp + " $3 "
+		} else {
+			query += "AND (" + keys + ") " + cmp + " (SELECT " + key + ", u.nickname " +
+				"FROM dbforum.users AS u " +
+				"LEFT JOIN dbforum.forum_users AS fu ON fu.nickname = u.nickname AND fu.forum_slug = $1 " +
+				"WHERE u.nickname = $3) "
+		}
+		limit = "$4"
+	}
+	query += "ORDER BY "
+	if key != "" {
+		query += key + order + ", "
+	}
+	return query + "fu.nickname" + order + " LIMIT " + limit
+}
+
+// forumUsersStatement имя подготовленного запроса forumUsersQuery.
+func forumUsersStatement(sort string, since, desc bool) string {
+	name := "selectForumUsers_" + sort
+	if since {
+		name += "Since"
+	}
+	if desc {
+		name += "Desc"
+	}
+	return name
+}
+
 type Repository struct {
 	db *pgx.ConnPool
 }
@@ -69,7 +283,18 @@ func NewRepo(db *pgx.ConnPool) *Repository {
 	}
 }
 
-func (r *Repository) GetForumUsers(forumSlug string, limit int, since string, desc bool) ([]models.User, error) {
+// GetForumUsers участники форума с сортировкой sort (ключ forumUserSorts) и
+// фильтром filter: пустой, moderators или banned.
+func (r *Repository) GetForumUsers(forumSlug string, limit int, since string, desc bool, sort string, filter string) ([]models.User, error) {
+	if sort == "" {
+		sort = "nickname"
+	}
+	if _, ok := forumUserSorts[sort]; !ok {
+		return nil, customErr.ErrUnknownSort
+	}
+	if filter != "" && filter != "moderators" && filter != "banned" {
+		return nil, customErr.ErrUnknownUsersFilter
+	}
 	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
@@ -86,18 +311,11 @@ func (r *Repository) GetForumUsers(forumSlug string, limit int, since string, de
 		return nil, customErr.ErrForumNotFound
 	}
 	row.Close()
+	query := forumUsersStatement(sort, since != "", desc)
 	if since == "" {
-		if desc {
-			row, err = r.db.Query("selectUsersByForumSlugDesc", forumSlug, limit)
-		} else {
-			row, err = r.db.Query("selectUsersByForumSlug", forumSlug, limit)
-		}
+		row, err = r.db.Query(query, forumSlug, filter, limit)
 	} else {
-		if desc {
-			row, err = r.db.Query("selectUsersByForumSlugSinceDesc", forumSlug, since, limit)
-		} else {
-			row, err = r.db.Query("selectUsersByForumSlugSince", forumSlug, since, limit)
-		}
+		row, err = r.db.Query(query, forumSlug, filter, since, limit)
 	}
 
 	if err != nil {
@@ -106,15 +324,13 @@ func (r *Repository) GetForumUsers(forumSlug string, limit int, since string, de
 	}
 	for row.Next() {
 		u := models.User{}
-		err := row.Scan(
-			&u.Nickname,
-			&u.Fullname,
-			&u.About,
-			&u.Email)
+		activity := models.ForumActivity{}
+		err := ScanUser(row, &u, &activity.Posts, &activity.Threads, &activity.FirstActive, &activity.LastActive)
 		if err != nil {
 			_ = tx.Rollback()
 			return nil, err
 		}
+		u.Activity = &activity
 		users = append(users, u)
 	}
 	row.Close()
@@ -125,14 +341,31 @@ func (r *Repository) GetForumUsers(forumSlug string, limit int, since string, de
 	return users, nil
 }
 
-func (r *Repository) CreateUser(user models.User) error {
-	_, err := r.db.Exec("insertUser", &user.Nickname, &user.Fullname, &user.About, &user.Email)
+// CreateUser создаёт пользователя. Никнейм, зарезервированный после
+// переименования позже reservedSince, занять нельзя.
+func (r *Repository) CreateUser(user models.User, passwordHash string, reservedSince time.Time, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return err
+	}
+	if err = reserveTx(tx, user.Nickname, "", reservedSince); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("insertUser", &user.Nickname, &user.Fullname, &user.About, &user.Email, passwordHash,
+		&user.Signature, &user.Website, &user.Location)
 	if driverErr, ok := err.(pgx.PgError); ok {
 		if driverErr.Code == "23505" {
+			_ = tx.Rollback()
 			return customErr.ErrDuplicate
 		}
 	}
 	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 	return nil
@@ -146,11 +379,7 @@ func (r *Repository) GetUsersByNickAndEmail(nickname string, email string) ([]mo
 	}
 	for rows.Next() {
 		u := models.User{}
-		err := rows.Scan(
-			&u.Nickname,
-			&u.Fullname,
-			&u.About,
-			&u.Email)
+		err := ScanUser(rows, &u)
 		if err != nil {
 			return nil, err
 		}
@@ -169,28 +398,38 @@ func (r *Repository) GetUserByNick(nickname string) (*models.User, error) {
 	if !rows.Next() {
 		return nil, customErr.ErrUserNotFound
 	}
-	err = rows.Scan(
-		&user.Nickname,
-		&user.Fullname,
-		&user.About,
-		&user.Email)
+	err = ScanUser(rows, &user)
+	rows.Close()
 	if err != nil {
 		return nil, err
 	}
-	rows.Close()
 	return &user, nil
 }
 
-func (r *Repository) ChangeUser(user *models.User) error {
-	tx, err := r.db.Begin()
+// ChangeUser применяет к профилю nickname изменённые поля update.
+func (r *Repository) ChangeUser(user *models.User, update models.UserUpdate, passwordHash string, actor models.Actor) error {
+	tx, err := auditRepo.Begin(r.db, actor)
 	if err != nil {
 		return err
 	}
-	err = tx.QueryRow("updateUser", &user.Fullname, &user.About, &user.Email, &user.Nickname).Scan(
-		&user.Nickname,
-		&user.Fullname,
-		&user.About,
-		&user.Email)
+	privacy := update.Privacy
+	if privacy == nil {
+		privacy = &models.PrivacyUpdate{}
+	}
+	err = ScanUser(tx.QueryRow("updateUser",
+		update.Fullname,
+		update.About,
+		update.Email,
+		user.Nickname,
+		passwordHash,
+		update.Signature,
+		update.Website,
+		update.Location,
+		privacy.HideEmail,
+		privacy.HideWebsite,
+		privacy.HideLocation,
+		privacy.HideLastSeen,
+		privacy.HideCounts), user)
 	if driverErr, ok := err.(pgx.PgError); ok {
 		if driverErr.Code == "23505" {
 			_ = tx.Rollback()
@@ -208,6 +447,169 @@ func (r *Repository) ChangeUser(user *models.User) error {
 	return nil
 }
 
+// RenameUser меняет никнейм во всех таблицах: внешние ключи обновляются
+// каскадно, остальное - триггером users_rename.
+func (r *Repository) RenameUser(nickname string, newNickname string, reservedSince time.Time, actor models.Actor) (*models.User, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.Exec("setRenaming"); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err = reserveTx(tx, newNickname, nickname, reservedSince); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	user := &models.User{}
+	err = ScanUser(tx.QueryRow("renameUser", nickname, newNickname), user)
+	if err == pgx.ErrNoRows {
+		_ = tx.Rollback()
+		return nil, customErr.ErrUserNotFound
+	}
+	if driverErr, ok := err.(pgx.PgError); ok && driverErr.Code == "23505" {
+		_ = tx.Rollback()
+		return nil, customErr.ErrNicknameTaken
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser обезличивает пользователя: никнейм меняется на псевдоним
+// каскадно во всех записях, личные данные, участие в форумах, роли,
+// подписки и перенаправления со старых никнеймов удаляются. Возвращает
+// псевдоним и прежний аватар.
+func (r *Repository) DeleteUser(nickname string, actor models.Actor) (string, string, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err = tx.Exec("setRenaming"); err != nil {
+		_ = tx.Rollback()
+		return "", "", err
+	}
+	var pseudonym, avatar string
+	err = tx.QueryRow("anonymizeUser", nickname).Scan(&pseudonym, &avatar)
+	if err == pgx.ErrNoRows {
+		_ = tx.Rollback()
+		return "", "", customErr.ErrUserNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return "", "", err
+	}
+	for _, name := range []string{
+		"deleteForumUsers",
+		"deleteUserRenames",
+		"deleteUserRoles",
+		"deleteUserThreadSubs",
+		"deleteUserForumSubs",
+		"deleteUserWatched",
+		"deleteUserBlocks",
+		"deleteUserNotifications",
+		"deleteUserMentions",
+		"leaveUserConversations",
+	} {
+		if _, err = tx.Exec(name, pseudonym); err != nil {
+			_ = tx.Rollback()
+			return "", "", err
+		}
+	}
+	if _, err = tx.Exec("setAuditRewrite"); err != nil {
+		_ = tx.Rollback()
+		return "", "", err
+	}
+	for _, name := range []string{"anonymizeAuditActor", "anonymizeAuditProfile"} {
+		if _, err = tx.Exec(name, nickname, pseudonym); err != nil {
+			_ = tx.Rollback()
+			return "", "", err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return "", "", err
+	}
+	return pseudonym, avatar, nil
+}
+
+func (r *Repository) GetUserVotes(nickname string) ([]models.ExportedVote, error) {
+	rows, err := r.db.Query("selectUserVotes", nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var votes []models.ExportedVote
+	for rows.Next() {
+		v := models.ExportedVote{}
+		if err := rows.Scan(&v.Thread, &v.Voice); err != nil {
+			return nil, err
+		}
+		votes = append(votes, v)
+	}
+	return votes, rows.Err()
+}
+
+// GetRenamed текущий никнейм пользователя, носившего nickname раньше.
+func (r *Repository) GetRenamed(nickname string) (string, error) {
+	var current string
+	err := r.db.QueryRow("selectRenamed", nickname).Scan(&current)
+	if err == pgx.ErrNoRows {
+		return "", customErr.ErrUserNotFound
+	}
+	return current, err
+}
+
+// reserveTx освобождает прежний никнейм nickname для owner: свой прежний
+// никнейм или никнейм с истёкшим резервом забирается, чужой действующий
+// резерв даёт ErrNicknameReserved.
+func reserveTx(tx *pgx.Tx, nickname string, owner string, reservedSince time.Time) error {
+	var holder string
+	var reserved bool
+	err := tx.QueryRow("selectReservation", nickname, reservedSince).Scan(&holder, &reserved)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if reserved && !strings.EqualFold(holder, owner) {
+		return customErr.ErrNicknameReserved
+	}
+	_, err = tx.Exec("deleteReservation", nickname)
+	return err
+}
+
+// SetAvatar сохраняет адрес аватара и возвращает прежний.
+func (r *Repository) SetAvatar(nickname string, avatar string, actor models.Actor) (string, error) {
+	tx, err := auditRepo.Begin(r.db, actor)
+	if err != nil {
+		return "", err
+	}
+	var old string
+	err = tx.QueryRow("updateAvatar", nickname, avatar).Scan(&old)
+	if err == pgx.ErrNoRows {
+		_ = tx.Rollback()
+		return "", customErr.ErrUserNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+	if err = tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+	return old, nil
+}
+
 func (r *Repository) GetUserNickByEmail(email string) (string, error) {
 	var nickname string
 	rows, err := r.db.Query(selectNickByEmail, email)
@@ -227,6 +629,152 @@ func (r *Repository) GetUserNickByEmail(email string) (string, error) {
 	return nickname, nil
 }
 
+// GetPassword возвращает никнейм в каноническом регистре и хеш пароля.
+func (r *Repository) GetPassword(nickname string) (string, string, error) {
+	var passwordHash string
+	rows, err := r.db.Query("selectPassword", nickname)
+	if err != nil {
+		return "", "", err
+	}
+	if !rows.Next() {
+		rows.Close()
+		return "", "", customErr.ErrUserNotFound
+	}
+	err = rows.Scan(&nickname, &passwordHash)
+	rows.Close()
+	if err != nil {
+		return "", "", err
+	}
+	return nickname, passwordHash, nil
+}
+
+// IsProtected проверяет, защищён ли аккаунт от действий без токена.
+// Несуществующий пользователь не защищён: его отсутствие сообщит сценарий.
+func (r *Repository) IsProtected(nickname string) (bool, error) {
+	var protected bool
+	err := r.db.QueryRow("selectProtected", nickname).Scan(&protected)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return protected, err
+}
+
+func (r *Repository) SearchUsers(pattern string, emailPattern string, limit int, since string, desc bool) ([]models.User, error) {
+	var rows *pgx.Rows
+	var err error
+	if desc {
+		rows, err = r.db.Query("searchUsersDesc", pattern, emailPattern, since, limit)
+	} else {
+		rows, err = r.db.Query("searchUsers", pattern, emailPattern, since, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	for rows.Next() {
+		u := models.User{}
+		err := ScanUser(rows, &u)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	rows.Close()
+	return users, nil
+}
+
+// RecomputeReputation пересчитывает репутацию всех пользователей по таблице
+// dbforum.votes и возвращает количество исправленных записей.
+func (r *Repository) RecomputeReputation() (int64, error) {
+	tag, err := r.db.Exec(recomputeReputation)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CheckForumUsers находит расхождения копий профилей в forum_users с
+// таблицами users, thread и post.
+func (r *Repository) CheckForumUsers() ([]models.ForumUserDrift, error) {
+	rows, err := r.db.Query(checkForumUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var drift []models.ForumUserDrift
+	for rows.Next() {
+		d := models.ForumUserDrift{}
+		if err := rows.Scan(&d.Forum, &d.Nickname, &d.Kind); err != nil {
+			return nil, err
+		}
+		drift = append(drift, d)
+	}
+	return drift, rows.Err()
+}
+
+// RepairForumUsers устраняет расхождения, найденные CheckForumUsers, в одной
+// транзакции и возвращает количество исправленных записей каждого вида.
+func (r *Repository) RepairForumUsers() (models.ForumUsersRepair, error) {
+	repair := models.ForumUsersRepair{}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return repair, err
+	}
+	defer tx.Rollback()
+
+	tag, err := tx.Exec(repairStaleForumUsers)
+	if err != nil {
+		return repair, err
+	}
+	repair.Stale = tag.RowsAffected()
+	tag, err = tx.Exec(repairMissingForumUsers)
+	if err != nil {
+		return repair, err
+	}
+	repair.Missing = tag.RowsAffected()
+	tag, err = tx.Exec(repairOrphanedForumUsers)
+	if err != nil {
+		return repair, err
+	}
+	repair.Orphaned = tag.RowsAffected()
+	return repair, tx.Commit()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ScanUser читает поля userColumns и следующие за ними extra. Другие
+// репозитории читают им профиль по подготовленному запросу selectByNickname.
+func ScanUser(row scanner, u *models.User, extra ...interface{}) error {
+	privacy := models.Privacy{}
+	dest := []interface{}{
+		&u.Nickname,
+		&u.Fullname,
+		&u.About,
+		&u.Email,
+		&u.Reputation,
+		&u.Avatar,
+		&u.Signature,
+		&u.Website,
+		&u.Location,
+		&u.Joined,
+		&u.LastSeen,
+		&u.Posts,
+		&u.Threads,
+		&privacy.HideEmail,
+		&privacy.HideWebsite,
+		&privacy.HideLocation,
+		&privacy.HideLastSeen,
+		&privacy.HideCounts,
+		&u.Deleted,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	u.Privacy = &privacy
+	return err
+}
+
 func (r *Repository) Prepare() error {
 	_, err := r.db.Prepare("insertUser", insertUser)
 	if err != nil {
@@ -238,26 +786,122 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
-	_, err = r.db.Prepare("selectUsersByForumSlugSinceDesc", selectUsersByForumSlugSinceDesc)
+	_, err = r.db.Prepare("updateAvatar", updateAvatar)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("setRenaming", setRenaming)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("renameUser", renameUser)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectReservation", selectReservation)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteReservation", deleteReservation)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectRenamed", selectRenamed)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("anonymizeUser", anonymizeUser)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteForumUsers", deleteForumUsers)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserRenames", deleteUserRenames)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserRoles", deleteUserRoles)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserThreadSubs", deleteUserThreadSubs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserForumSubs", deleteUserForumSubs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserWatched", deleteUserWatched)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserBlocks", deleteUserBlocks)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserNotifications", deleteUserNotifications)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("deleteUserMentions", deleteUserMentions)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Prepare("selectUsersByForumSlugSince", selectUsersByForumSlugSince)
+	_, err = r.db.Prepare("leaveUserConversations", leaveUserConversations)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Prepare("selectUsersByForumSlugDesc", selectUsersByForumSlugDesc)
+	_, err = r.db.Prepare("setAuditRewrite", setAuditRewrite)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Prepare("selectUsersByForumSlug", selectUsersByForumSlug)
+	_, err = r.db.Prepare("anonymizeAuditActor", anonymizeAuditActor)
 	if err != nil {
 		return err
 	}
 
+	_, err = r.db.Prepare("anonymizeAuditProfile", anonymizeAuditProfile)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectUserVotes", selectUserVotes)
+	if err != nil {
+		return err
+	}
+
+	for sort := range forumUserSorts {
+		for _, since := range []bool{false, true} {
+			for _, desc := range []bool{false, true} {
+				_, err = r.db.Prepare(forumUsersStatement(sort, since, desc), forumUsersQuery(sort, since, desc))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	_, err = r.db.Prepare("checkForumExist", "SELECT 1 FROM dbforum.forum WHERE slug = $1 LIMIT 1")
 	if err != nil {
 		return err
@@ -268,5 +912,25 @@ func (r *Repository) Prepare() error {
 		return err
 	}
 
+	_, err = r.db.Prepare("selectPassword", selectPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("selectProtected", selectProtected)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("searchUsers", searchUsers)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Prepare("searchUsersDesc", searchUsersDesc)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AvatarPrefix адрес, по которому сервер отдаёт файлы хранилища аватаров.
+const AvatarPrefix = "/avatars/"
+
+// Store хранилище файлов аватаров. Put возвращает адрес сохранённого файла,
+// Delete принимает такой адрес. Замена локального диска на объектное
+// хранилище сводится к другой реализации.
+type Store interface {
+	Put(ext string, data []byte) (string, error)
+	Delete(url string) error
+}
+
+// Disk хранит аватары в каталоге на локальном диске.
+type Disk struct {
+	dir string
+}
+
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Disk{
+		dir: dir,
+	}, nil
+}
+
+// Put сохраняет файл под случайным именем: адрес меняется с каждой
+// загрузкой, и закешированный клиентами старый аватар не показывается.
+func (d *Disk) Put(ext string, data []byte) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(buf) + ext
+	if err := ioutil.WriteFile(filepath.Join(d.dir, name), data, 0o644); err != nil {
+		return "", err
+	}
+	return AvatarPrefix + name, nil
+}
+
+func (d *Disk) Delete(url string) error {
+	name := strings.TrimPrefix(url, AvatarPrefix)
+	if name == url || name == "" || strings.ContainsAny(name, `/\`) {
+		return nil
+	}
+	err := os.Remove(filepath.Join(d.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
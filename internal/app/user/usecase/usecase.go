@@ -1,22 +1,93 @@
 package usecase
 
 import (
+	"DBForum/internal/app/config"
+	customErr "DBForum/internal/app/errors"
 	"DBForum/internal/app/models"
+	postRepo "DBForum/internal/app/post/repository"
+	roleUseCase "DBForum/internal/app/role/usecase"
+	threadRepo "DBForum/internal/app/thread/repository"
 	userRepo "DBForum/internal/app/user/repository"
+	"DBForum/internal/app/user/storage"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Никнейм из символов, которые распознают упоминания и маршруты.
+var nicknamePattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// Начало никнеймов и домен почты обезличенных аккаунтов, см. anonymizeUser.
+// Другим аккаунтам они недоступны, иначе удаление упрётся в уникальность.
+const (
+	deletedPrefix      = "deleted-"
+	deletedEmailDomain = "@invalid"
+)
+
+// Размер страницы, которой читаются записи при выгрузке данных.
+const exportPage = 1000
+
+// Допустимые форматы аватара и расширения их файлов.
+var avatarTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
 type UseCase struct {
-	repo userRepo.Repository
+	repo       userRepo.Repository
+	threadRepo threadRepo.Repository
+	postRepo   postRepo.Repository
+	avatars    storage.Store
+	avatar     config.Avatar
+	rename     config.RenamePolicy
 }
 
-func NewUseCase(repo userRepo.Repository) *UseCase {
+func NewUseCase(repo userRepo.Repository, threadRepo threadRepo.Repository, postRepo postRepo.Repository, avatars storage.Store, avatar config.Avatar, rename config.RenamePolicy) *UseCase {
 	return &UseCase{
-		repo: repo,
+		repo:       repo,
+		threadRepo: threadRepo,
+		postRepo:   postRepo,
+		avatars:    avatars,
+		avatar:     avatar,
+		rename:     rename,
 	}
 }
 
-func (u *UseCase) CreateUser(user models.User) error {
-	err := u.repo.CreateUser(user)
+// CreateUser создаёт пользователя. Аватар, счётчики и даты ведёт сервер,
+// переданные в запросе значения отбрасываются.
+func (u *UseCase) CreateUser(user *models.User, password string, actor models.Actor) error {
+	if !nicknamePattern.MatchString(user.Nickname) || isDeletedNickname(user.Nickname) {
+		return customErr.ErrInvalidNickname
+	}
+	if isDeletedEmail(user.Email) {
+		return customErr.ErrEmailReserved
+	}
+	if err := checkWebsite(user.Website); err != nil {
+		return err
+	}
+	*user = models.User{
+		Nickname:  user.Nickname,
+		Fullname:  user.Fullname,
+		About:     user.About,
+		Email:     user.Email,
+		Signature: user.Signature,
+		Website:   user.Website,
+		Location:  user.Location,
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	err = u.repo.CreateUser(*user, passwordHash, u.reservedSince(), actor)
 	if err != nil {
 		return err
 	}
@@ -28,22 +99,250 @@ func (u *UseCase) GetUsersByNickAndEmail(nickname string, email string) ([]model
 	if err != nil {
 		return nil, err
 	}
+	// Ответ на конфликт при регистрации видит кто угодно.
+	for i := range users {
+		ApplyPrivacy(&users[i], "")
+	}
 	return users, nil
 }
 
-func (u *UseCase) GetUserInfo(nickname string) (*models.User, error) {
+// GetUserInfo профиль пользователя; скрытые им поля видны только ему самому.
+// Прежний никнейм ведёт на профиль под текущим.
+func (u *UseCase) GetUserInfo(nickname string, viewer string) (*models.User, error) {
 	user, err := u.repo.GetUserByNick(nickname)
+	if errors.Is(err, customErr.ErrUserNotFound) {
+		var current string
+		if current, err = u.repo.GetRenamed(nickname); err != nil {
+			return nil, err
+		}
+		user, err = u.repo.GetUserByNick(current)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ApplyPrivacy(user, viewer)
+	return user, nil
+}
+
+// ApplyPrivacy убирает из профиля поля, скрытые владельцем от viewer, и сами
+// настройки приватности.
+func ApplyPrivacy(user *models.User, viewer string) {
+	privacy := user.Privacy
+	if privacy == nil || strings.EqualFold(user.Nickname, viewer) {
+		return
+	}
+	user.Privacy = nil
+	if privacy.HideEmail {
+		user.Email = ""
+	}
+	if privacy.HideWebsite {
+		user.Website = ""
+	}
+	if privacy.HideLocation {
+		user.Location = ""
+	}
+	if privacy.HideLastSeen {
+		user.LastSeen = nil
+	}
+	if privacy.HideCounts {
+		user.Posts = 0
+		user.Threads = 0
+		if user.Activity != nil {
+			user.Activity.Posts = 0
+			user.Activity.Threads = 0
+		}
+	}
+}
+
+// ChangeUser обновляет профиль от имени actor: меняются только переданные в
+// update поля. Профиль с паролем может менять только его владелец.
+func (u *UseCase) ChangeUser(nickname string, update models.UserUpdate, password string, actor models.Actor) (*models.User, error) {
+	// Пароль закрывает аккаунт от запросов без токена, поэтому задать его
+	// может только владелец с токеном или администратор.
+	if password != "" && actor.Nickname == "" {
+		return nil, customErr.ErrForbidden
+	}
+	if !roleUseCase.Allowed(actor.Roles, roleUseCase.PermAdmin, "") {
+		if err := u.checkOwner(nickname, actor); err != nil {
+			return nil, err
+		}
+	}
+	if update.Email != nil && isDeletedEmail(*update.Email) {
+		return nil, customErr.ErrEmailReserved
+	}
+	if update.Website != nil {
+		if err := checkWebsite(*update.Website); err != nil {
+			return nil, err
+		}
+	}
+	passwordHash, err := hashPassword(password)
 	if err != nil {
 		return nil, err
 	}
+	user := &models.User{Nickname: nickname}
+	if err = u.repo.ChangeUser(user, update, passwordHash, actor); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
-func (u *UseCase) ChangeUser(user *models.User) error {
-	err := u.repo.ChangeUser(user)
+// RenameUser меняет никнейм пользователя. Прежний никнейм остаётся
+// перенаправлением на новый и закрепляется за владельцем.
+func (u *UseCase) RenameUser(nickname string, rename models.Rename, actor models.Actor) (*models.User, error) {
+	if !nicknamePattern.MatchString(rename.Nickname) || isDeletedNickname(rename.Nickname) {
+		return nil, customErr.ErrInvalidNickname
+	}
+	// Переименование резервирует никнейм и отзывает сессии, анонимно оно
+	// недоступно даже для аккаунтов без пароля.
+	if actor.Nickname == "" {
+		return nil, customErr.ErrForbidden
+	}
+	if err := u.checkOwner(nickname, actor); err != nil {
+		return nil, err
+	}
+	return u.repo.RenameUser(nickname, rename.Nickname, u.reservedSince(), actor)
+}
+
+// DeleteUser удаляет аккаунт с обезличиванием. Удалить аккаунт может его
+// владелец или администратор.
+func (u *UseCase) DeleteUser(nickname string, actor models.Actor) (*models.User, error) {
+	// Удаление необратимо: без токена его не выполнить даже для аккаунта
+	// без пароля.
+	if actor.Nickname == "" {
+		return nil, customErr.ErrForbidden
+	}
+	if !roleUseCase.Allowed(actor.Roles, roleUseCase.PermAdmin, "") {
+		if err := u.checkOwner(nickname, actor); err != nil {
+			return nil, err
+		}
+	}
+	pseudonym, avatar, err := u.repo.DeleteUser(nickname, actor)
+	if err != nil {
+		return nil, err
+	}
+	if avatar != "" {
+		if err := u.avatars.Delete(avatar); err != nil {
+			log.Println(err)
+		}
+	}
+	return u.repo.GetUserByNick(pseudonym)
+}
+
+// ExportUser собирает профиль, сообщения, ветки и голоса пользователя.
+// Выгрузка доступна владельцу и администратору.
+func (u *UseCase) ExportUser(nickname string, actor models.Actor) (models.UserExport, error) {
+	if !strings.EqualFold(actor.Nickname, nickname) && !roleUseCase.Allowed(actor.Roles, roleUseCase.PermAdmin, "") {
+		return models.UserExport{}, customErr.ErrForbidden
+	}
+	var export models.UserExport
+	var err error
+	if export.Profile, err = u.repo.GetUserByNick(nickname); err != nil {
+		return models.UserExport{}, err
+	}
+	nickname = export.Profile.Nickname
+	for since := uint64(0); ; {
+		posts, err := u.postRepo.ExportUserPosts(nickname, exportPage, since)
+		if err != nil {
+			return models.UserExport{}, err
+		}
+		export.Posts = append(export.Posts, posts...)
+		if len(posts) < exportPage {
+			break
+		}
+		since = posts[len(posts)-1].ID
+	}
+	for since := uint64(0); ; {
+		threads, err := u.threadRepo.ExportUserThreads(nickname, exportPage, since)
+		if err != nil {
+			return models.UserExport{}, err
+		}
+		export.Threads = append(export.Threads, threads...)
+		if len(threads) < exportPage {
+			break
+		}
+		since = threads[len(threads)-1].ID
+	}
+	if export.Votes, err = u.repo.GetUserVotes(nickname); err != nil {
+		return models.UserExport{}, err
+	}
+	if export.Posts == nil {
+		export.Posts = models.PostList{}
+	}
+	if export.Threads == nil {
+		export.Threads = models.ThreadList{}
+	}
+	if export.Votes == nil {
+		export.Votes = models.ExportedVoteList{}
+	}
+	return export, nil
+}
+
+// reservedSince переименования после этого момента ещё держат резерв.
+func (u *UseCase) reservedSince() time.Time {
+	return time.Now().Add(-u.rename.Reservation)
+}
+
+// SetAvatar сохраняет загруженное изображение аватаром пользователя. Формат
+// определяется по содержимому, а не по заголовку запроса.
+func (u *UseCase) SetAvatar(nickname string, data []byte, actor models.Actor) (*models.User, error) {
+	if err := u.checkOwner(nickname, actor); err != nil {
+		return nil, err
+	}
+	if len(data) > u.avatar.MaxSize {
+		return nil, customErr.ErrAvatarTooLarge
+	}
+	ext, ok := avatarTypes[http.DetectContentType(data)]
+	if !ok {
+		return nil, customErr.ErrAvatarType
+	}
+	avatar, err := u.avatars.Put(ext, data)
+	if err != nil {
+		return nil, err
+	}
+	return u.replaceAvatar(nickname, avatar, actor)
+}
+
+func (u *UseCase) DeleteAvatar(nickname string, actor models.Actor) (*models.User, error) {
+	if err := u.checkOwner(nickname, actor); err != nil {
+		return nil, err
+	}
+	return u.replaceAvatar(nickname, "", actor)
+}
+
+// replaceAvatar записывает новый адрес и удаляет прежний файл. Если запись
+// не удалась, удаляется новый файл.
+func (u *UseCase) replaceAvatar(nickname string, avatar string, actor models.Actor) (*models.User, error) {
+	old, err := u.repo.SetAvatar(nickname, avatar, actor)
+	if err != nil {
+		if avatar != "" {
+			if err := u.avatars.Delete(avatar); err != nil {
+				log.Println(err)
+			}
+		}
+		return nil, err
+	}
+	if old != "" {
+		if err := u.avatars.Delete(old); err != nil {
+			log.Println(err)
+		}
+	}
+	return u.repo.GetUserByNick(nickname)
+}
+
+// checkOwner профиль с паролем может менять только его владелец, профиль без
+// пароля - и анонимный вызывающий.
+func (u *UseCase) checkOwner(nickname string, actor models.Actor) error {
+	caller := actor.Nickname
+	_, currentHash, err := u.repo.GetPassword(nickname)
 	if err != nil {
 		return err
 	}
+	if caller != "" && !strings.EqualFold(caller, nickname) {
+		return customErr.ErrForbidden
+	}
+	if caller == "" && currentHash != "" {
+		return customErr.ErrForbidden
+	}
 	return nil
 }
 
@@ -54,3 +353,107 @@ func (u *UseCase) GetUserNickByEmail(email string) (string, error) {
 	}
 	return nickname, nil
 }
+
+// SearchUsers ищет пользователей по никнейму или полному имени (query) и по
+// почте (email). match задаёт вид сравнения: prefix (по умолчанию) или substring.
+func (u *UseCase) SearchUsers(query string, email string, match string, limit int, since string, desc bool, viewer string) ([]models.User, error) {
+	users, err := u.repo.SearchUsers(likePattern(query, match), likePattern(email, match), limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		ApplyPrivacy(&users[i], viewer)
+	}
+	if users == nil {
+		return []models.User{}, nil
+	}
+	return users, nil
+}
+
+func likePattern(value string, match string) string {
+	if value == "" {
+		return ""
+	}
+	value = likeEscaper.Replace(value)
+	if match == "substring" {
+		return "%" + value + "%"
+	}
+	return value + "%"
+}
+
+func (u *UseCase) GetUserThreads(nickname string, forumSlug string, limit int, since uint64, desc bool) ([]models.Thread, error) {
+	threads, err := u.threadRepo.GetUserThreads(nickname, forumSlug, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	if threads == nil {
+		return []models.Thread{}, nil
+	}
+	return threads, nil
+}
+
+func (u *UseCase) GetUserPosts(nickname string, forumSlug string, limit int, since uint64, desc bool) ([]models.Post, error) {
+	posts, err := u.postRepo.GetUserPosts(nickname, forumSlug, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	if posts == nil {
+		return []models.Post{}, nil
+	}
+	return posts, nil
+}
+
+func (u *UseCase) RecomputeReputation() (int64, error) {
+	updated, err := u.repo.RecomputeReputation()
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+func (u *UseCase) CheckForumUsers() ([]models.ForumUserDrift, error) {
+	drift, err := u.repo.CheckForumUsers()
+	if err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
+
+func (u *UseCase) RepairForumUsers() (models.ForumUsersRepair, error) {
+	repair, err := u.repo.RepairForumUsers()
+	if err != nil {
+		return models.ForumUsersRepair{}, err
+	}
+	return repair, nil
+}
+
+func isDeletedNickname(nickname string) bool {
+	return strings.HasPrefix(strings.ToLower(nickname), deletedPrefix)
+}
+
+func isDeletedEmail(email string) bool {
+	return strings.HasSuffix(strings.ToLower(email), deletedEmailDomain)
+}
+
+// checkWebsite сайт в профиле - пустая строка или адрес http(s).
+func checkWebsite(website string) error {
+	if website == "" {
+		return nil
+	}
+	parsed, err := url.Parse(website)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return customErr.ErrInvalidWebsite
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -1,20 +1,119 @@
 ALTER USER postgres WITH ENCRYPTED PASSWORD 'admin';
 DROP SCHEMA IF EXISTS dbforum CASCADE;
 CREATE EXTENSION IF NOT EXISTS citext;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 CREATE SCHEMA dbforum;
 
+-- Конфигурация полнотекстового поиска задаётся сервером через параметр
+-- соединения dbforum.search_language.
+CREATE OR REPLACE FUNCTION dbforum.search_language() RETURNS regconfig AS
+$$
+SELECT COALESCE(NULLIF(current_setting('dbforum.search_language', true), ''), 'simple')::regconfig;
+$$ LANGUAGE sql STABLE;
+
 CREATE UNLOGGED TABLE dbforum.users
 (
-    id       BIGSERIAL PRIMARY KEY NOT NULL,
+    id         BIGSERIAL PRIMARY KEY NOT NULL,
+
+    nickname   CITEXT UNIQUE         NOT NULL,
+    fullname   TEXT                  NOT NULL,
+    about      TEXT                  NOT NULL,
+    email      CITEXT UNIQUE         NOT NULL,
+    reputation BIGINT DEFAULT 0      NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    -- Пустой хеш означает, что вход по паролю для пользователя не настроен.
+    password   TEXT   DEFAULT ''     NOT NULL,
+
+    avatar     TEXT   DEFAULT ''     NOT NULL,
+    signature  TEXT   DEFAULT ''     NOT NULL,
+    website    TEXT   DEFAULT ''     NOT NULL,
+    location   TEXT   DEFAULT ''     NOT NULL,
+    -- Время последней записи пользователя: сообщения, ветки, голоса.
+    last_seen  TIMESTAMP WITH TIME ZONE,
+    posts      BIGINT DEFAULT 0      NOT NULL,
+    threads    BIGINT DEFAULT 0      NOT NULL,
+
+    hide_email     BOOLEAN DEFAULT false NOT NULL,
+    hide_website   BOOLEAN DEFAULT false NOT NULL,
+    hide_location  BOOLEAN DEFAULT false NOT NULL,
+    hide_last_seen BOOLEAN DEFAULT false NOT NULL,
+    hide_counts    BOOLEAN DEFAULT false NOT NULL,
 
-    nickname CITEXT UNIQUE         NOT NULL,
-    fullname TEXT                  NOT NULL,
-    about    TEXT                  NOT NULL,
-    email    CITEXT UNIQUE         NOT NULL
+    -- Время удаления аккаунта. Удалённый пользователь обезличен: записи
+    -- остаются под псевдонимом deleted-<id>, личные данные стёрты.
+    deleted        TIMESTAMP WITH TIME ZONE
 );
 
 create index user_nickname_idx on dbforum.users (nickname);
 create index user_email_idx on dbforum.users (email);
+create index user_reputation_nickname_idx on dbforum.users (reputation, nickname);
+create index user_nickname_trgm_idx on dbforum.users using gin ((nickname::text) gin_trgm_ops);
+create index user_fullname_trgm_idx on dbforum.users using gin (fullname gin_trgm_ops);
+create index user_email_trgm_idx on dbforum.users using gin ((email::text) gin_trgm_ops);
+
+CREATE UNLOGGED TABLE dbforum.sessions
+(
+    id           BIGSERIAL PRIMARY KEY    NOT NULL,
+    nickname     CITEXT                   NOT NULL,
+    refresh_hash TEXT UNIQUE              NOT NULL,
+    expires      TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked      TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
+);
+
+create index sessions_nickname_idx on dbforum.sessions (nickname);
+
+-- Прежние никнеймы. nickname следует за последующими переименованиями,
+-- поэтому старое имя всегда ведёт на текущее. Имя зарезервировано за
+-- прежним владельцем на срок, заданный настройками сервера.
+CREATE UNLOGGED TABLE dbforum.user_renames
+(
+    old_nickname CITEXT PRIMARY KEY                     NOT NULL,
+    nickname     CITEXT                                 NOT NULL,
+    renamed      TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+create index user_renames_nickname_idx on dbforum.user_renames (nickname);
+
+-- Роли сверх обычного пользователя. forum_slug заполняется только для
+-- модератора форума.
+CREATE UNLOGGED TABLE dbforum.roles
+(
+    nickname   CITEXT                                 NOT NULL,
+    role       TEXT                                   NOT NULL,
+    forum_slug CITEXT                   DEFAULT ''    NOT NULL,
+    granted_by CITEXT                   DEFAULT ''    NOT NULL,
+    granted    TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+
+    PRIMARY KEY (nickname, role, forum_slug)
+);
+
+-- Пустой forum_slug означает глобальный бан, пустой expires - бессрочный.
+CREATE UNLOGGED TABLE dbforum.bans
+(
+    id         BIGSERIAL PRIMARY KEY                  NOT NULL,
+    nickname   CITEXT                                 NOT NULL,
+    forum_slug CITEXT                   DEFAULT ''    NOT NULL,
+    reason     TEXT                     DEFAULT ''    NOT NULL,
+    banned_by  CITEXT                   DEFAULT ''    NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    expires    TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+
+    UNIQUE (nickname, forum_slug)
+);
+
+create index bans_forum_slug_id_idx on dbforum.bans (forum_slug, id);
 
 CREATE UNLOGGED TABLE dbforum.forum
 (
@@ -27,11 +126,17 @@ CREATE UNLOGGED TABLE dbforum.forum
     threads       INT    DEFAULT 0      NOT NULL,
 
     FOREIGN KEY (user_nickname)
-        REFERENCES dbforum.users (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
 );
 
 create index forum_slug_idx on dbforum.forum (slug);
 
+-- Порядок публикации веток и сообщений для ленты подписок. Номер published
+-- выдаётся, когда запись впервые становится видимой: при создании или при
+-- одобрении задержанной фильтром записи. Пустой published - запись ещё не
+-- публиковалась.
+CREATE SEQUENCE dbforum.published_seq;
+
 CREATE UNLOGGED TABLE dbforum.thread
 (
     id              BIGSERIAL PRIMARY KEY    NOT NULL,
@@ -43,16 +148,27 @@ CREATE UNLOGGED TABLE dbforum.thread
     votes           INT DEFAULT 0            NOT NULL,
     slug            citext UNIQUE,
     created         TIMESTAMP WITH TIME ZONE NOT NULL,
+    search          TSVECTOR                 NOT NULL,
+    -- Скрытые модератором ветки не попадают в списки и поиск.
+    hidden          BOOLEAN DEFAULT false    NOT NULL,
+    -- Правки ведёт триггер thread_revision, прежние версии - в thread_revisions.
+    edit_count      INT     DEFAULT 0        NOT NULL,
+    last_edited     TIMESTAMP WITH TIME ZONE,
+    last_editor     CITEXT  DEFAULT ''       NOT NULL,
+    published       BIGINT,
 
     FOREIGN KEY (forum_slug)
         REFERENCES dbforum.forum (slug),
     FOREIGN KEY (author_nickname)
-        REFERENCES dbforum.users (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
 );
 create index thread_forum_slug_idx on dbforum.thread (forum_slug);
+create index thread_published_idx on dbforum.thread (published);
 create index thread_slug_id_forum_slug_idx on dbforum.thread (slug, id, forum_slug);
 create index thread_slug_idx on dbforum.thread (slug);
 create index thread_created_idx on dbforum.thread (created);
+create index thread_search_idx on dbforum.thread using gin (search);
+create index thread_author_nickname_created_id_idx on dbforum.thread (author_nickname, created, id);
 
 CREATE UNLOGGED TABLE dbforum.votes
 (
@@ -62,13 +178,27 @@ CREATE UNLOGGED TABLE dbforum.votes
     PRIMARY KEY (nickname, thread_id),
 
     FOREIGN KEY (nickname)
-        REFERENCES dbforum.users (nickname),
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
     FOREIGN KEY (thread_id)
         REFERENCES dbforum.thread (id)
 );
 
 create index votes_thread_id_nickname_voice_idx on dbforum.votes (thread_id, nickname, voice);
 
+CREATE UNLOGGED TABLE dbforum.vote_changes
+(
+    nickname  CITEXT                                 NOT NULL,
+    thread_id BIGINT                                 NOT NULL,
+    changed   TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+    FOREIGN KEY (thread_id)
+        REFERENCES dbforum.thread (id)
+);
+
+create index vote_changes_nickname_changed_idx on dbforum.vote_changes (nickname, changed);
+
 CREATE UNLOGGED TABLE dbforum.post
 (
     id              BIGSERIAL PRIMARY KEY               NOT NULL,
@@ -81,9 +211,15 @@ CREATE UNLOGGED TABLE dbforum.post
     is_edited       BOOLEAN  DEFAULT false              NOT NULL,
     created         TIMESTAMP WITH TIME ZONE            NOT NULL,
     tree            BIGINT[] DEFAULT ARRAY []::BIGINT[] NOT NULL,
+    search          TSVECTOR                            NOT NULL,
+    hidden          BOOLEAN  DEFAULT false              NOT NULL,
+    edit_count      INT      DEFAULT 0                  NOT NULL,
+    last_edited     TIMESTAMP WITH TIME ZONE,
+    last_editor     CITEXT   DEFAULT ''                 NOT NULL,
+    published       BIGINT,
 
     FOREIGN KEY (author_nickname)
-        REFERENCES dbforum.users (nickname),
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
     FOREIGN KEY (forum_slug)
         REFERENCES dbforum.forum (slug),
     FOREIGN KEY (thread_id)
@@ -91,10 +227,265 @@ CREATE UNLOGGED TABLE dbforum.post
 );
 
 create index posts_thread_id_parent_idx on dbforum.post (thread_id, parent);
+create index posts_published_idx on dbforum.post (published);
 create index posts_tree_1_id_idx on dbforum.post ((tree[1]), id);
 create index posts_tree_1_desc_tree_id_idx on dbforum.post ((tree[1]) DESC, tree, id);
 create index posts_tree_id_idx on dbforum.post (tree, id);
 create index posts_tree_idx on dbforum.post using gin (tree);
+create index posts_search_idx on dbforum.post using gin (search);
+create index posts_author_nickname_created_id_idx on dbforum.post (author_nickname, created, id);
+
+-- Прежние версии сообщений и веток. Версии нумеруются с 1 (исходный текст),
+-- текущая версия имеет номер edit_count + 1 и хранится в самой записи.
+CREATE UNLOGGED TABLE dbforum.post_revisions
+(
+    post_id  BIGINT                   NOT NULL,
+    revision INT                      NOT NULL,
+    message  TEXT                     NOT NULL,
+    editor   CITEXT                   NOT NULL,
+    created  TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    FOREIGN KEY (post_id)
+        REFERENCES dbforum.post (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (post_id, revision)
+);
+
+CREATE UNLOGGED TABLE dbforum.thread_revisions
+(
+    thread_id BIGINT                   NOT NULL,
+    revision  INT                      NOT NULL,
+    title     TEXT                     NOT NULL,
+    message   TEXT                     NOT NULL,
+    editor    CITEXT                   NOT NULL,
+    created   TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    FOREIGN KEY (thread_id)
+        REFERENCES dbforum.thread (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (thread_id, revision)
+);
+
+-- Упоминания пользователей (@nickname) в сообщениях. Ведутся триггером
+-- post_mentions при создании и правке сообщения.
+CREATE UNLOGGED TABLE dbforum.mentions
+(
+    id       BIGSERIAL PRIMARY KEY                  NOT NULL,
+    post_id  BIGINT                                 NOT NULL,
+    nickname CITEXT                                 NOT NULL,
+    created  TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (post_id)
+        REFERENCES dbforum.post (id) ON DELETE CASCADE,
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+
+    UNIQUE (post_id, nickname)
+);
+
+create index mentions_nickname_id_idx on dbforum.mentions (nickname, id);
+
+-- Уведомления пользователя nickname о действиях actor: reply - ответ на
+-- сообщение, thread_reply - сообщение в ветке пользователя, mention -
+-- упоминание, vote - голос за ветку. Создаются триггерами.
+CREATE UNLOGGED TABLE dbforum.notifications
+(
+    id         BIGSERIAL PRIMARY KEY                  NOT NULL,
+    nickname   CITEXT                                 NOT NULL,
+    type       TEXT                                   NOT NULL,
+    actor      CITEXT                                 NOT NULL,
+    post_id    BIGINT                   DEFAULT 0     NOT NULL,
+    thread_id  BIGINT                                 NOT NULL,
+    forum_slug CITEXT                                 NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    read       TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
+);
+
+create index notifications_nickname_id_idx on dbforum.notifications (nickname, id);
+create index notifications_unread_idx on dbforum.notifications (nickname, type) WHERE read IS NULL;
+
+-- Подписки на ветки и форумы.
+CREATE UNLOGGED TABLE dbforum.thread_subscriptions
+(
+    nickname  CITEXT                                 NOT NULL,
+    thread_id BIGINT                                 NOT NULL,
+    created   TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+    FOREIGN KEY (thread_id)
+        REFERENCES dbforum.thread (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (nickname, thread_id)
+);
+
+create index thread_subscriptions_thread_id_idx on dbforum.thread_subscriptions (thread_id);
+
+CREATE UNLOGGED TABLE dbforum.forum_subscriptions
+(
+    nickname   CITEXT                                 NOT NULL,
+    forum_slug CITEXT                                 NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+    FOREIGN KEY (forum_slug)
+        REFERENCES dbforum.forum (slug),
+
+    PRIMARY KEY (nickname, forum_slug)
+);
+
+-- Последние сообщение и ветка, показанные пользователю в ленте подписок, -
+-- их номера published. Строка заводится при первой подписке, лента
+-- начинается с этого момента.
+CREATE UNLOGGED TABLE dbforum.watched_visits
+(
+    nickname    CITEXT PRIMARY KEY                     NOT NULL,
+    last_post   BIGINT                                 NOT NULL,
+    last_thread BIGINT                                 NOT NULL,
+    visited     TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
+);
+
+-- Блокировки и скрытия. Записи blocked сворачиваются или скрываются для
+-- nickname; при kind = 'block' blocked также не может отвечать nickname,
+-- упоминать его и писать ему личные сообщения.
+CREATE UNLOGGED TABLE dbforum.user_blocks
+(
+    nickname CITEXT                                   NOT NULL,
+    blocked  CITEXT                                   NOT NULL,
+    kind     TEXT                     DEFAULT 'block' NOT NULL,
+    created  TIMESTAMP WITH TIME ZONE DEFAULT now()   NOT NULL,
+
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+    FOREIGN KEY (blocked)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+
+    PRIMARY KEY (nickname, blocked)
+);
+
+create index user_blocks_blocked_idx on dbforum.user_blocks (blocked);
+
+-- Личные переписки двух и более пользователей. last_message - последнее
+-- сообщение, по нему сортируется список переписок.
+CREATE UNLOGGED TABLE dbforum.conversations
+(
+    id           BIGSERIAL PRIMARY KEY                  NOT NULL,
+    title        TEXT                     DEFAULT ''    NOT NULL,
+    created_by   CITEXT                                 NOT NULL,
+    created      TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    last_message BIGINT                   DEFAULT 0     NOT NULL,
+
+    FOREIGN KEY (created_by)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
+);
+
+-- Участники переписки. left заполняется при выходе, last_read - последнее
+-- прочитанное сообщение.
+CREATE UNLOGGED TABLE dbforum.conversation_members
+(
+    conversation_id BIGINT                                 NOT NULL,
+    nickname        CITEXT                                 NOT NULL,
+    joined          TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    left_at         TIMESTAMP WITH TIME ZONE,
+    last_read       BIGINT                   DEFAULT 0     NOT NULL,
+
+    FOREIGN KEY (conversation_id)
+        REFERENCES dbforum.conversations (id) ON DELETE CASCADE,
+    FOREIGN KEY (nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+
+    PRIMARY KEY (conversation_id, nickname)
+);
+
+create index conversation_members_nickname_idx on dbforum.conversation_members (nickname) WHERE left_at IS NULL;
+
+CREATE UNLOGGED TABLE dbforum.private_messages
+(
+    id              BIGSERIAL PRIMARY KEY                  NOT NULL,
+    conversation_id BIGINT                                 NOT NULL,
+    author_nickname CITEXT                                 NOT NULL,
+    message         TEXT                                   NOT NULL,
+    created         TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (conversation_id)
+        REFERENCES dbforum.conversations (id) ON DELETE CASCADE,
+    FOREIGN KEY (author_nickname)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE
+);
+
+create index private_messages_conversation_id_idx on dbforum.private_messages (conversation_id, id);
+
+-- Жалобы на сообщения (target_type = 'post') и ветки ('thread').
+-- Пустой resolved означает, что жалоба ждёт решения модератора, пустой
+-- (NULL) reporter - что запись задержал фильтр содержимого.
+CREATE UNLOGGED TABLE dbforum.reports
+(
+    id              BIGSERIAL PRIMARY KEY                  NOT NULL,
+    target_type     TEXT                                   NOT NULL,
+    target_id       BIGINT                                 NOT NULL,
+    forum_slug      CITEXT                                 NOT NULL,
+    author_nickname CITEXT                                 NOT NULL,
+    reporter        CITEXT,
+    reason          TEXT                     DEFAULT ''    NOT NULL,
+    created         TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    resolved        TIMESTAMP WITH TIME ZONE,
+    resolution      TEXT                     DEFAULT ''    NOT NULL,
+    resolved_by     CITEXT                   DEFAULT ''    NOT NULL,
+
+    FOREIGN KEY (reporter)
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
+    FOREIGN KEY (forum_slug)
+        REFERENCES dbforum.forum (slug)
+);
+
+create unique index reports_open_reporter_idx on dbforum.reports (target_type, target_id, reporter) WHERE resolved IS NULL;
+create index reports_open_forum_slug_idx on dbforum.reports (forum_slug, target_type, target_id) WHERE resolved IS NULL;
+
+-- Запрещённые в форуме слова. action: reject - отклонить запись, mask -
+-- заменить слово звёздочками, hold - задержать запись до решения модератора.
+CREATE UNLOGGED TABLE dbforum.forum_word_filters
+(
+    forum_slug CITEXT                                 NOT NULL,
+    word       CITEXT                                 NOT NULL,
+    action     TEXT                                   NOT NULL,
+    created    TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (forum_slug)
+        REFERENCES dbforum.forum (slug),
+
+    PRIMARY KEY (forum_slug, word)
+);
+
+-- Журнал аудита только пополняется: строки пишут триггеры dbforum.audit_row()
+-- и сценарии модерации. Инициатор и идентификатор запроса передаются сервером
+-- через параметры транзакции dbforum.actor и dbforum.request_id.
+CREATE UNLOGGED TABLE dbforum.audit_log
+(
+    id          BIGSERIAL PRIMARY KEY                  NOT NULL,
+    actor       CITEXT                                 NOT NULL,
+    action      TEXT                                   NOT NULL,
+    target_type TEXT                                   NOT NULL,
+    target_id   TEXT                                   NOT NULL,
+    forum_slug  CITEXT                   DEFAULT ''    NOT NULL,
+    details     TEXT                     DEFAULT ''    NOT NULL,
+    -- Только изменившиеся поля; before пуст при создании, after - при удалении.
+    before      JSONB,
+    after       JSONB,
+    request_id  TEXT                     DEFAULT COALESCE(current_setting('dbforum.request_id', true), '') NOT NULL,
+    created     TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+create index audit_log_actor_id_idx on dbforum.audit_log (actor, id);
+create index audit_log_target_id_idx on dbforum.audit_log (target_type, target_id, id);
+create index audit_log_forum_slug_id_idx on dbforum.audit_log (forum_slug, id);
+create index audit_log_request_id_idx on dbforum.audit_log (request_id);
 
 CREATE UNLOGGED TABLE dbforum.forum_users
 (
@@ -103,28 +494,100 @@ CREATE UNLOGGED TABLE dbforum.forum_users
     fullname   TEXT   NOT NULL,
     about      TEXT   NOT NULL,
     email      TEXT   NOT NULL,
+    -- Активность в форуме ведёт триггер insert_forum_user.
+    posts        INT                      DEFAULT 0 NOT NULL,
+    threads      INT                      DEFAULT 0 NOT NULL,
+    first_active TIMESTAMP WITH TIME ZONE           NOT NULL,
+    last_active  TIMESTAMP WITH TIME ZONE           NOT NULL,
 
     FOREIGN KEY (nickname)
-        REFERENCES dbforum.users (nickname),
+        REFERENCES dbforum.users (nickname) ON UPDATE CASCADE,
     FOREIGN KEY (forum_slug)
         REFERENCES dbforum.forum (slug),
 
     PRIMARY KEY (nickname, forum_slug)
 );
 create index forum_users_forum_slug_idx on dbforum.forum_users (forum_slug);
+create index forum_users_forum_slug_posts_idx on dbforum.forum_users (forum_slug, posts, nickname);
+create index forum_users_forum_slug_first_active_idx on dbforum.forum_users (forum_slug, first_active, nickname);
+create index forum_users_forum_slug_last_active_idx on dbforum.forum_users (forum_slug, last_active, nickname);
 
+-- Аргумент - счётчик forum_users, который увеличивают вставленные строки:
+-- posts или threads. Строки оператора из таблицы переходов changed сводятся
+-- по автору и форуму, так что каждая запись участника обновляется один раз
+-- за оператор и в порядке первичного ключа.
 CREATE OR REPLACE FUNCTION dbforum.insert_forum_user() RETURNS TRIGGER AS
 $$
+DECLARE
+    is_post INT = CASE WHEN TG_ARGV[0] = 'posts' THEN 1 ELSE 0 END;
 BEGIN
-    INSERT INTO dbforum.forum_users(forum_slug, nickname, fullname, about, email)
-    SELECT NEW.forum_slug, nickname, fullname, about, email
-    FROM dbforum.users
-    WHERE nickname = NEW.author_nickname
-    ON CONFLICT DO NOTHING;
-    RETURN NEW;
+    INSERT INTO dbforum.forum_users AS fu(forum_slug, nickname, fullname, about, email,
+                                          posts, threads, first_active, last_active)
+    SELECT a.forum_slug, u.nickname, u.fullname, u.about, u.email,
+           is_post * a.n, (1 - is_post) * a.n, a.first_active, a.last_active
+    FROM (SELECT forum_slug, author_nickname, count(*)::int AS n,
+                 min(created) AS first_active, max(created) AS last_active
+          FROM changed
+          GROUP BY forum_slug, author_nickname) AS a
+             JOIN dbforum.users AS u ON u.nickname = a.author_nickname
+    ORDER BY u.nickname, a.forum_slug
+    ON CONFLICT (nickname, forum_slug) DO UPDATE
+        SET posts        = fu.posts + EXCLUDED.posts,
+            threads      = fu.threads + EXCLUDED.threads,
+            first_active = LEAST(fu.first_active, EXCLUDED.first_active),
+            last_active  = GREATEST(fu.last_active, EXCLUDED.last_active);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+
+-- Уменьшает счётчики forum_users на удалённые строки из таблицы переходов
+-- changed; аргумент - posts или threads. Участник, у которого не осталось
+-- веток и сообщений в форуме, удаляется, даты активности остальных не
+-- пересчитываются.
+CREATE OR REPLACE FUNCTION dbforum.delete_forum_user() RETURNS TRIGGER AS
+$$
+DECLARE
+    is_post INT = CASE WHEN TG_ARGV[0] = 'posts' THEN 1 ELSE 0 END;
+BEGIN
+    PERFORM 1
+    FROM dbforum.forum_users
+    WHERE (nickname, forum_slug) IN (SELECT author_nickname, forum_slug FROM changed)
+    ORDER BY nickname, forum_slug
+    FOR UPDATE;
+
+    UPDATE dbforum.forum_users AS fu
+    SET posts   = fu.posts - is_post * a.n,
+        threads = fu.threads - (1 - is_post) * a.n
+    FROM (SELECT forum_slug, author_nickname, count(*)::int AS n
+          FROM changed
+          GROUP BY forum_slug, author_nickname) AS a
+    WHERE fu.forum_slug = a.forum_slug
+      AND fu.nickname = a.author_nickname;
+
+    DELETE
+    FROM dbforum.forum_users
+    WHERE (nickname, forum_slug) IN (SELECT author_nickname, forum_slug FROM changed)
+      AND posts <= 0
+      AND threads <= 0;
+    RETURN NULL;
 END;
 $$ LANGUAGE plpgsql;
 
+-- Переносит изменения профиля в копии forum_users. Никнейм обновляется
+-- каскадно по внешнему ключу.
+CREATE OR REPLACE FUNCTION dbforum.sync_forum_users() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE dbforum.forum_users
+    SET fullname = NEW.fullname,
+        about    = NEW.about,
+        email    = NEW.email
+    WHERE nickname = NEW.nickname
+      AND (fullname, about, email) IS DISTINCT FROM (NEW.fullname, NEW.about, NEW.email::text);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
 
 CREATE OR REPLACE FUNCTION dbforum.update_forum_threads() RETURNS TRIGGER AS
 $$
@@ -147,11 +610,240 @@ BEGIN
 END
 $$ LANGUAGE plpgsql;
 
+-- Ведёт счётчики и last_seen пользователей по таблице переходов changed:
+-- одно обновление на оператор, а не на строку. Аргументы: поле автора и
+-- счётчик (posts, threads), без второго аргумента обновляется только
+-- last_seen. Вставка увеличивает счётчик и обновляет last_seen, удаление
+-- только уменьшает счётчик. Строки пользователей блокируются в порядке
+-- никнеймов, так что пакетные вставки с разными авторами не ловят взаимную
+-- блокировку.
+CREATE OR REPLACE FUNCTION dbforum.touch_user() RETURNS TRIGGER AS
+$$
+DECLARE
+    counter TEXT = CASE WHEN TG_NARGS > 1 THEN TG_ARGV[1] ELSE '' END;
+    delta   INT  = CASE WHEN TG_OP = 'DELETE' THEN -1 ELSE 1 END;
+BEGIN
+    PERFORM 1
+    FROM dbforum.users
+    WHERE nickname IN (SELECT (to_jsonb(c) ->> TG_ARGV[0])::citext FROM changed c)
+    ORDER BY nickname
+    FOR UPDATE;
+
+    UPDATE dbforum.users AS u
+    SET posts     = u.posts + CASE WHEN counter = 'posts' THEN delta * t.n ELSE 0 END,
+        threads   = u.threads + CASE WHEN counter = 'threads' THEN delta * t.n ELSE 0 END,
+        last_seen = CASE WHEN TG_OP = 'DELETE' THEN u.last_seen ELSE now() END
+    FROM (SELECT (to_jsonb(c) ->> TG_ARGV[0])::citext AS nickname, count(*) AS n
+          FROM changed c
+          GROUP BY 1) AS t
+    WHERE u.nickname = t.nickname;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Повторный голос обновляет last_seen, как и новый. Строки сопоставляются по
+-- ключу, поэтому каскадное переименование голосующего его не трогает.
+CREATE OR REPLACE FUNCTION dbforum.touch_voters() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM 1
+    FROM dbforum.users
+    WHERE nickname IN (SELECT n.nickname
+                       FROM new_votes n
+                                JOIN old_votes o ON o.nickname = n.nickname AND o.thread_id = n.thread_id)
+    ORDER BY nickname
+    FOR UPDATE;
+
+    UPDATE dbforum.users
+    SET last_seen = now()
+    WHERE nickname IN (SELECT n.nickname
+                       FROM new_votes n
+                                JOIN old_votes o ON o.nickname = n.nickname AND o.thread_id = n.thread_id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION dbforum.update_thread_search() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.search = setweight(to_tsvector(dbforum.search_language(), NEW.title), 'A') ||
+                 setweight(to_tsvector(dbforum.search_language(), NEW.message), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION dbforum.update_post_search() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.search = to_tsvector(dbforum.search_language(), NEW.message);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Сохраняет прежнюю версию при изменении текста. Редактор берётся из
+-- dbforum.actor, без него правка приписывается автору.
+CREATE OR REPLACE FUNCTION dbforum.save_post_revision() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.message IS NOT DISTINCT FROM OLD.message THEN
+        RETURN NEW;
+    END IF;
+    INSERT INTO dbforum.post_revisions(post_id, revision, message, editor, created)
+    VALUES (OLD.id, OLD.edit_count + 1, OLD.message,
+            COALESCE(NULLIF(OLD.last_editor, ''), OLD.author_nickname),
+            COALESCE(OLD.last_edited, OLD.created));
+    NEW.edit_count = OLD.edit_count + 1;
+    NEW.last_edited = now();
+    NEW.last_editor = COALESCE(NULLIF(current_setting('dbforum.actor', true), ''), NEW.author_nickname);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION dbforum.save_thread_revision() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.title IS NOT DISTINCT FROM OLD.title AND NEW.message IS NOT DISTINCT FROM OLD.message THEN
+        RETURN NEW;
+    END IF;
+    INSERT INTO dbforum.thread_revisions(thread_id, revision, title, message, editor, created)
+    VALUES (OLD.id, OLD.edit_count + 1, OLD.title, OLD.message,
+            COALESCE(NULLIF(OLD.last_editor, ''), OLD.author_nickname),
+            COALESCE(OLD.last_edited, OLD.created));
+    NEW.edit_count = OLD.edit_count + 1;
+    NEW.last_edited = now();
+    NEW.last_editor = COALESCE(NULLIF(current_setting('dbforum.actor', true), ''), NEW.author_nickname);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Существующие пользователи, упомянутые в тексте, кроме автора и
+-- заблокировавших его. Упоминание начинается с @ в начале текста или после
+-- символа, не входящего в никнейм; точка в конце считается концом
+-- предложения, если без неё никнейм найден.
+CREATE OR REPLACE FUNCTION dbforum.mentioned(message TEXT, author CITEXT) RETURNS SETOF CITEXT AS
+$$
+SELECT DISTINCT u.nickname
+FROM regexp_matches(message, '(^|[^A-Za-z0-9_.])@([A-Za-z0-9_.]+)', 'g') AS m
+         JOIN dbforum.users AS u
+              ON u.nickname = m[2]::citext OR u.nickname = rtrim(m[2], '.')::citext
+WHERE u.nickname <> author
+  AND NOT EXISTS(SELECT 1
+                 FROM dbforum.user_blocks AS b
+                 WHERE b.nickname = u.nickname
+                   AND b.blocked = author
+                   AND b.kind = 'block');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION dbforum.update_post_mentions() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.message = OLD.message THEN
+            RETURN NULL;
+        END IF;
+        DELETE
+        FROM dbforum.mentions
+        WHERE post_id = NEW.id
+          AND nickname NOT IN (SELECT dbforum.mentioned(NEW.message, NEW.author_nickname));
+    END IF;
+    IF position('@' in NEW.message) > 0 THEN
+        INSERT INTO dbforum.mentions(post_id, nickname)
+        SELECT NEW.id, dbforum.mentioned(NEW.message, NEW.author_nickname)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Ответ на сообщение уведомляет автора родителя, сообщение в ветке - автора
+-- ветки и её подписчиков, если они не получили уведомление об ответе.
+-- Задержанные фильтром сообщения уведомляют, когда их одобрят, вместе с
+-- упомянутыми в них пользователями; повторное одобрение скрытого
+-- модератором сообщения не уведомляет.
+CREATE OR REPLACE FUNCTION dbforum.notify_post() RETURNS TRIGGER AS
+$$
+DECLARE
+    parent_author CITEXT;
+BEGIN
+    IF NEW.published IS NULL THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE' THEN
+        IF OLD.published IS NOT NULL THEN
+            RETURN NULL;
+        END IF;
+        INSERT INTO dbforum.notifications(nickname, type, actor, post_id, thread_id, forum_slug)
+        SELECT m.nickname, 'mention', NEW.author_nickname, NEW.id, NEW.thread_id, NEW.forum_slug
+        FROM dbforum.mentions AS m
+        WHERE m.post_id = NEW.id;
+    END IF;
+    IF NEW.parent <> 0 THEN
+        SELECT author_nickname INTO parent_author FROM dbforum.post WHERE id = NEW.parent;
+        IF parent_author <> NEW.author_nickname THEN
+            INSERT INTO dbforum.notifications(nickname, type, actor, post_id, thread_id, forum_slug)
+            VALUES (parent_author, 'reply', NEW.author_nickname, NEW.id, NEW.thread_id, NEW.forum_slug);
+        END IF;
+    END IF;
+    INSERT INTO dbforum.notifications(nickname, type, actor, post_id, thread_id, forum_slug)
+    SELECT w.nickname, 'thread_reply', NEW.author_nickname, NEW.id, NEW.thread_id, NEW.forum_slug
+    FROM (SELECT t.author_nickname AS nickname
+          FROM dbforum.thread AS t
+          WHERE t.id = NEW.thread_id
+          UNION
+          SELECT s.nickname
+          FROM dbforum.thread_subscriptions AS s
+          WHERE s.thread_id = NEW.thread_id) AS w
+    WHERE w.nickname <> NEW.author_nickname
+      AND w.nickname IS DISTINCT FROM parent_author;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION dbforum.publish() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NOT NEW.hidden AND NEW.published IS NULL THEN
+        NEW.published = nextval('dbforum.published_seq');
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION dbforum.notify_mention() RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO dbforum.notifications(nickname, type, actor, post_id, thread_id, forum_slug)
+    SELECT NEW.nickname, 'mention', p.author_nickname, p.id, p.thread_id, p.forum_slug
+    FROM dbforum.post AS p
+    WHERE p.id = NEW.post_id
+      AND NOT p.hidden;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Голос и его изменение уведомляют автора ветки.
+CREATE OR REPLACE FUNCTION dbforum.notify_vote() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.voice = OLD.voice THEN
+        RETURN NULL;
+    END IF;
+    INSERT INTO dbforum.notifications(nickname, type, actor, thread_id, forum_slug)
+    SELECT t.author_nickname, 'vote', NEW.nickname, t.id, t.forum_slug
+    FROM dbforum.thread AS t
+    WHERE t.id = NEW.thread_id
+      AND t.author_nickname <> NEW.nickname;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
 
 CREATE OR REPLACE FUNCTION dbforum.insert_thread_vote() RETURNS TRIGGER AS
 $$
 BEGIN
     UPDATE dbforum.thread SET votes=(votes + NEW.voice) WHERE id = NEW.thread_id;
+    UPDATE dbforum.users
+    SET reputation = reputation + NEW.voice
+    WHERE nickname = (SELECT author_nickname FROM dbforum.thread WHERE id = NEW.thread_id);
     RETURN NEW;
 END
 $$ LANGUAGE plpgsql;
@@ -164,10 +856,117 @@ BEGIN
     ELSE
         UPDATE dbforum.thread SET votes=(votes - 2) WHERE id = NEW.thread_id;
     END IF;
+    UPDATE dbforum.users
+    SET reputation = reputation + NEW.voice - OLD.voice
+    WHERE nickname = (SELECT author_nickname FROM dbforum.thread WHERE id = NEW.thread_id);
     RETURN NEW;
 END
 $$ LANGUAGE plpgsql;
 
+-- Переносит никнейм в поля без внешнего ключа, запоминает прежний никнейм и
+-- отзывает сессии: токены доступа несут старый никнейм. Журнал аудита
+-- сохраняет имена на момент действия.
+CREATE OR REPLACE FUNCTION dbforum.rename_user() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.nickname::text = OLD.nickname::text THEN
+        RETURN NULL;
+    END IF;
+    UPDATE dbforum.thread SET last_editor = NEW.nickname WHERE last_editor = OLD.nickname;
+    UPDATE dbforum.post SET last_editor = NEW.nickname WHERE last_editor = OLD.nickname;
+    UPDATE dbforum.thread_revisions SET editor = NEW.nickname WHERE editor = OLD.nickname;
+    UPDATE dbforum.post_revisions SET editor = NEW.nickname WHERE editor = OLD.nickname;
+    UPDATE dbforum.roles SET granted_by = NEW.nickname WHERE granted_by = OLD.nickname;
+    UPDATE dbforum.bans SET banned_by = NEW.nickname WHERE banned_by = OLD.nickname;
+    UPDATE dbforum.notifications SET actor = NEW.nickname WHERE actor = OLD.nickname;
+    UPDATE dbforum.reports SET author_nickname = NEW.nickname WHERE author_nickname = OLD.nickname;
+    UPDATE dbforum.reports SET resolved_by = NEW.nickname WHERE resolved_by = OLD.nickname;
+    UPDATE dbforum.sessions SET revoked = now() WHERE nickname = NEW.nickname AND revoked IS NULL;
+    -- Смена только регистра букв имя не освобождает.
+    IF NEW.nickname <> OLD.nickname THEN
+        INSERT INTO dbforum.user_renames(old_nickname, nickname)
+        VALUES (OLD.nickname, NEW.nickname)
+        ON CONFLICT (old_nickname) DO UPDATE SET nickname = EXCLUDED.nickname, renamed = EXCLUDED.renamed;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Аргументы: тип объекта, поле идентификатора, поле автора (инициатор, если
+-- сервер его не передал) и поле форума. Производные счётчики и служебные
+-- поля в журнал не попадают, изменения только в них не записываются.
+CREATE OR REPLACE FUNCTION dbforum.audit_row() RETURNS TRIGGER AS
+$$
+DECLARE
+    old_row  JSONB;
+    new_row  JSONB;
+    rec      JSONB;
+    old_diff JSONB;
+    new_diff JSONB;
+BEGIN
+    -- При переименовании пишется только изменение самого пользователя, не
+    -- каскадные изменения его записей.
+    IF TG_OP = 'UPDATE' AND TG_TABLE_NAME <> 'users'
+        AND current_setting('dbforum.renaming', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP <> 'INSERT' THEN
+        old_row = to_jsonb(OLD) - 'search' - 'tree' - 'votes' - 'reputation' - 'posts' - 'threads'
+                   - 'edit_count' - 'last_edited' - 'last_editor' - 'last_seen' - 'published';
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row = to_jsonb(NEW) - 'search' - 'tree' - 'votes' - 'reputation' - 'posts' - 'threads'
+                   - 'edit_count' - 'last_edited' - 'last_editor' - 'last_seen' - 'published';
+    END IF;
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(o.key, o.value)
+        INTO old_diff
+        FROM jsonb_each(old_row) AS o
+        WHERE new_row -> o.key IS DISTINCT FROM o.value;
+        IF old_diff IS NULL THEN
+            RETURN NULL;
+        END IF;
+        SELECT jsonb_object_agg(n.key, n.value)
+        INTO new_diff
+        FROM jsonb_each(new_row) AS n
+        WHERE old_row -> n.key IS DISTINCT FROM n.value;
+    ELSE
+        old_diff = old_row;
+        new_diff = new_row;
+    END IF;
+    -- Смена пароля видна в журнале, сам хеш - нет.
+    IF old_diff ? 'password' THEN
+        old_diff = jsonb_set(old_diff, '{password}', '"***"');
+    END IF;
+    IF new_diff ? 'password' THEN
+        new_diff = jsonb_set(new_diff, '{password}', '"***"');
+    END IF;
+    rec = COALESCE(new_row, old_row);
+    INSERT INTO dbforum.audit_log(actor, action, target_type, target_id, forum_slug, before, after)
+    VALUES (COALESCE(NULLIF(current_setting('dbforum.actor', true), ''), rec ->> TG_ARGV[2], ''),
+            TG_ARGV[0] || '.' || lower(TG_OP),
+            TG_ARGV[0],
+            rec ->> TG_ARGV[1],
+            COALESCE(rec ->> TG_ARGV[3], ''),
+            old_diff,
+            new_diff);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Журнал аудита только пополняется. Единственное исключение - обезличивание
+-- при удалении аккаунта: оно переписывает записи пользователя на псевдоним,
+-- установив dbforum.audit_rewrite = 'on' до конца своей транзакции.
+CREATE OR REPLACE FUNCTION dbforum.protect_audit_log() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('dbforum.audit_rewrite', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'dbforum.audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+
 CREATE TRIGGER insert_voice
     AFTER INSERT
     ON dbforum.votes
@@ -176,12 +975,18 @@ EXECUTE FUNCTION dbforum.insert_thread_vote();
 
 
 CREATE TRIGGER update_voice
-    AFTER UPDATE
+    AFTER UPDATE OF voice
     ON dbforum.votes
     FOR EACH ROW
 EXECUTE FUNCTION dbforum.update_thread_vote();
 
 
+CREATE TRIGGER vote_notify
+    AFTER INSERT OR UPDATE OF voice
+    ON dbforum.votes
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.notify_vote();
+
 CREATE TRIGGER thread_insert
     AFTER INSERT
     ON dbforum.thread
@@ -191,8 +996,63 @@ EXECUTE FUNCTION dbforum.update_forum_threads();
 CREATE TRIGGER thread_insert_user_forum
     AFTER INSERT
     ON dbforum.thread
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.insert_forum_user('threads');
+
+CREATE TRIGGER thread_search
+    BEFORE INSERT OR UPDATE OF title, message
+    ON dbforum.thread
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.update_thread_search();
+
+CREATE TRIGGER post_search
+    BEFORE INSERT OR UPDATE OF message
+    ON dbforum.post
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.update_post_search();
+
+CREATE TRIGGER post_revision
+    BEFORE UPDATE OF message
+    ON dbforum.post
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.save_post_revision();
+
+CREATE TRIGGER thread_revision
+    BEFORE UPDATE OF title, message
+    ON dbforum.thread
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.save_thread_revision();
+
+CREATE TRIGGER post_mentions
+    AFTER INSERT OR UPDATE OF message
+    ON dbforum.post
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.update_post_mentions();
+
+CREATE TRIGGER post_publish
+    BEFORE INSERT OR UPDATE OF hidden
+    ON dbforum.post
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.publish();
+
+CREATE TRIGGER thread_publish
+    BEFORE INSERT OR UPDATE OF hidden
+    ON dbforum.thread
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.publish();
+
+CREATE TRIGGER post_notify
+    AFTER INSERT OR UPDATE OF hidden
+    ON dbforum.post
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.notify_post();
+
+CREATE TRIGGER mention_notify
+    AFTER INSERT
+    ON dbforum.mentions
     FOR EACH ROW
-EXECUTE FUNCTION dbforum.insert_forum_user();
+EXECUTE FUNCTION dbforum.notify_mention();
 
 CREATE TRIGGER post_insert
     BEFORE INSERT
@@ -203,5 +1063,143 @@ EXECUTE FUNCTION dbforum.update_forum_posts();
 CREATE TRIGGER post_insert_forum_usert
     AFTER INSERT
     ON dbforum.post
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.insert_forum_user('posts');
+
+CREATE TRIGGER post_delete_forum_user
+    AFTER DELETE
+    ON dbforum.post
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.delete_forum_user('posts');
+
+CREATE TRIGGER thread_delete_forum_user
+    AFTER DELETE
+    ON dbforum.thread
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.delete_forum_user('threads');
+
+-- Таблицы переходов не допускают нескольких событий в одном триггере,
+-- поэтому вставка и удаление заведены отдельно.
+CREATE TRIGGER post_touch_user
+    AFTER INSERT
+    ON dbforum.post
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_user('author_nickname', 'posts');
+
+CREATE TRIGGER post_delete_touch_user
+    AFTER DELETE
+    ON dbforum.post
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_user('author_nickname', 'posts');
+
+CREATE TRIGGER thread_touch_user
+    AFTER INSERT
+    ON dbforum.thread
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_user('author_nickname', 'threads');
+
+CREATE TRIGGER thread_delete_touch_user
+    AFTER DELETE
+    ON dbforum.thread
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_user('author_nickname', 'threads');
+
+CREATE TRIGGER votes_touch_user
+    AFTER INSERT
+    ON dbforum.votes
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_user('nickname');
+
+CREATE TRIGGER votes_update_touch_user
+    AFTER UPDATE
+    ON dbforum.votes
+    REFERENCING OLD TABLE AS old_votes NEW TABLE AS new_votes
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_voters();
+
+CREATE TRIGGER private_messages_touch_user
+    AFTER INSERT
+    ON dbforum.private_messages
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION dbforum.touch_user('author_nickname');
+
+CREATE TRIGGER users_sync_forum_users
+    AFTER UPDATE OF fullname, about, email
+    ON dbforum.users
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.sync_forum_users();
+
+CREATE TRIGGER users_rename
+    AFTER UPDATE OF nickname
+    ON dbforum.users
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.rename_user();
+
+CREATE TRIGGER users_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.users
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('user', 'nickname', 'nickname', '');
+
+CREATE TRIGGER forum_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.forum
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('forum', 'slug', 'user_nickname', 'slug');
+
+CREATE TRIGGER thread_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.thread
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('thread', 'id', 'author_nickname', 'forum_slug');
+
+CREATE TRIGGER post_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.post
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('post', 'id', 'author_nickname', 'forum_slug');
+
+CREATE TRIGGER votes_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.votes
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('vote', 'thread_id', 'nickname', '');
+
+CREATE TRIGGER bans_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.bans
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('ban', 'nickname', 'banned_by', 'forum_slug');
+
+CREATE TRIGGER roles_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.roles
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('role', 'nickname', 'granted_by', 'forum_slug');
+
+CREATE TRIGGER reports_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.reports
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('report', 'id', 'reporter', 'forum_slug');
+
+CREATE TRIGGER forum_word_filters_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON dbforum.forum_word_filters
+    FOR EACH ROW
+EXECUTE FUNCTION dbforum.audit_row('word_filter', 'word', '', 'forum_slug');
+
+CREATE TRIGGER audit_log_protect
+    BEFORE UPDATE OR DELETE
+    ON dbforum.audit_log
     FOR EACH ROW
-EXECUTE FUNCTION dbforum.insert_forum_user();
+EXECUTE FUNCTION dbforum.protect_audit_log();